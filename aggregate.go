@@ -0,0 +1,203 @@
+package lol
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// maxAggregateKeys bounds how many distinct messages a window holds. Messages
+// beyond it are written straight through, so a flood of unique messages cannot
+// use up memory.
+const maxAggregateKeys = 1000
+
+// aggregators holds the aggregator for each level, nil when it has none.
+var aggregators [Trace + 1]atomic.Pointer[aggregator]
+
+type aggregate struct {
+	p *printer
+	e *Entry
+	n int
+}
+
+type aggregator struct {
+	sync.Mutex
+	window time.Duration
+	counts map[string]*aggregate
+	// order keeps the messages in the order they were first seen.
+	order []string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// SetAggregateWindow turns lines at level into a periodic report: instead of
+// being written, each distinct message from each call site is counted, and at
+// the end of every window d one line per message is written as
+// "message (count=N over d)". A d of zero or less turns it off for the level,
+// writing out what has been counted so far.
+func SetAggregateWindow(level int, d time.Duration) {
+	lv := clampLevel(level)
+	var a *aggregator
+	if d > 0 {
+		a = &aggregator{
+			window: d,
+			counts: make(map[string]*aggregate),
+			stop:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		go a.run()
+	}
+	if old := aggregators[lv].Swap(a); old != nil {
+		close(old.stop)
+		<-old.done
+	}
+}
+
+// SetDedupKeyFunc sets the function that decides which lines are the same for
+// coalescing them, as SetAggregateWindow does: lines with the same key are
+// counted as one, reported with the message of the first of them. Returning a
+// key without the parts of msg that vary, such as a request ID, collapses
+// lines that are the same apart from those. Passing nil restores the default,
+// which keys on the code location and the full message.
+func SetDedupKeyFunc(fn func(level int, loc, msg string) string) {
+	update(func(c *config) { c.dedupKey = fn })
+}
+
+// dedupKeyOf is the key that e is coalesced with others under.
+func (c *config) dedupKeyOf(e *Entry) string {
+	if c.dedupKey != nil {
+		return c.dedupKey(e.LevelID, e.CodeLocation, e.Text)
+	}
+	return e.CodeLocation + "\x00" + e.Text
+}
+
+// repeats holds the last line written while SetDedup is on, with how many
+// times it has come in a row.
+var repeats struct {
+	sync.Mutex
+	p   *printer
+	e   *Entry
+	key string
+	n   int
+}
+
+// SetDedup turns collapsing of repeated lines on or off. While it is on, a
+// line that is the same as the one before it, at the same level from the same
+// Log, is not written but counted, as keyed by SetDedupKeyFunc, and once a
+// different line comes, or on Flush, the last of the repeats is written with
+// "(xN)" after it for the N times the line came in a row. Turning it off
+// writes the count held so far.
+func SetDedup(enabled bool) {
+	update(func(c *config) { c.dedup = enabled })
+	if !enabled {
+		Flush()
+	}
+}
+
+// Flush writes the count of a line repeated while SetDedup is on, from the
+// lines held so far.
+func Flush() {
+	repeats.Lock()
+	p, e, n := repeats.p, repeats.e, repeats.n
+	repeats.p, repeats.e, repeats.key, repeats.n = nil, nil, "", 0
+	repeats.Unlock()
+	writeRepeats(p, e, n)
+}
+
+// writeRepeats writes e, which came n times in a row, if it was held back.
+func writeRepeats(p *printer, e *Entry, n int) {
+	if n < 2 {
+		return
+	}
+	r := *e
+	r.Text = fmt.Sprintf("%s (x%d)", r.Text, n)
+	p.write(loadConfig(), &r)
+}
+
+// repeated reports whether e is the same as the line before it while SetDedup
+// is on, in which case it is counted and must not be written now. When it is
+// not, the count of the line before, if held, is written first.
+func repeated(c *config, p *printer, e *Entry) bool {
+	if !c.dedup {
+		return false
+	}
+	key := c.dedupKeyOf(e)
+	repeats.Lock()
+	if repeats.p != nil && repeats.key == key &&
+		repeats.p.level == p.level && sameWriter(repeats.p.writer, p.writer) {
+		repeats.p, repeats.e = p, e
+		repeats.n++
+		repeats.Unlock()
+		return true
+	}
+	lp, le, n := repeats.p, repeats.e, repeats.n
+	repeats.p, repeats.e, repeats.key, repeats.n = p, e, key, 1
+	repeats.Unlock()
+	writeRepeats(lp, le, n)
+	return false
+}
+
+// sameWriter is whether a and b are the same writer, which writers that cannot
+// be compared never are.
+func sameWriter(a, b io.Writer) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	return ta == tb && ta != nil && ta.Comparable() && a == b
+}
+
+// aggregated counts e if its level is being aggregated, reporting whether it
+// was, in which case it must not be written now.
+func aggregated(c *config, p *printer, e *Entry) bool {
+	a := aggregators[e.LevelID].Load()
+	if a == nil {
+		return false
+	}
+	key := c.dedupKeyOf(e)
+	a.Lock()
+	defer a.Unlock()
+	if g, ok := a.counts[key]; ok {
+		g.n++
+		return true
+	}
+	if len(a.counts) >= maxAggregateKeys {
+		return false
+	}
+	a.counts[key] = &aggregate{p: p, e: e, n: 1}
+	a.order = append(a.order, key)
+	return true
+}
+
+func (a *aggregator) run() {
+	defer close(a.done)
+	t := time.NewTicker(a.window)
+	defer t.Stop()
+	for {
+		select {
+		case <-a.stop:
+			a.flush()
+			return
+		case <-t.C:
+			a.flush()
+		}
+	}
+}
+
+// flush writes a line for each message counted in the window and starts the
+// next one empty.
+func (a *aggregator) flush() {
+	a.Lock()
+	counts, order := a.counts, a.order
+	a.counts, a.order = make(map[string]*aggregate), nil
+	a.Unlock()
+	now := loadConfig().now()
+	for _, key := range order {
+		g := counts[key]
+		e := *g.e
+		e.Time = now
+		e.Text = fmt.Sprintf("%s (count=%d over %v)", e.Text, g.n, a.window)
+		g.p.write(loadConfig(), &e)
+	}
+}
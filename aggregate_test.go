@@ -0,0 +1,88 @@
+package lol_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestAggregateWindow(t *testing.T) {
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	lol.SetAggregateWindow(lol.Warn, time.Hour)
+	for i := 0; i < 3; i++ {
+		l.W.Ln("disk low")
+		l.W.Ln("fan slow")
+	}
+	l.E.Ln("not aggregated")
+	if got := buf.String(); strings.Contains(got, "disk low") ||
+		!strings.Contains(got, "not aggregated") {
+		t.Fatalf("unexpected output inside the window:\n%s", got)
+	}
+	lol.SetAggregateWindow(lol.Warn, 0)
+	got := buf.String()
+	if !strings.Contains(got, "disk low (count=3 over 1h0m0s)") ||
+		!strings.Contains(got, "fan slow (count=3 over 1h0m0s)") {
+		t.Fatalf("counts not written when turned off:\n%s", got)
+	}
+	l.W.Ln("after")
+	if !strings.Contains(buf.String(), "after") {
+		t.Fatalf("lines still held after turning aggregation off")
+	}
+}
+
+func TestDedupKeyFunc(t *testing.T) {
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	lol.SetDedupKeyFunc(func(level int, loc, msg string) string {
+		msg, _, _ = strings.Cut(msg, " request=")
+		return msg
+	})
+	defer lol.SetDedupKeyFunc(nil)
+	lol.SetAggregateWindow(lol.Warn, time.Hour)
+	for _, id := range []string{"a1", "b2", "c3"} {
+		l.W.Ln("timed out request=" + id)
+	}
+	lol.SetAggregateWindow(lol.Warn, 0)
+	if got := buf.String(); strings.Count(got, "timed out") != 1 ||
+		!strings.Contains(got, "timed out request=a1 (count=3 over 1h0m0s)") {
+		t.Fatalf("lines not coalesced by key:\n%s", got)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	lol.SetDedup(true)
+	for i := 0; i < 4; i++ {
+		l.W.Ln("retrying")
+	}
+	l.E.Ln("retrying")
+	l.W.Ln("once")
+	for i := 0; i < 2; i++ {
+		l.W.Ln("twice")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[1], " WRN retrying (x4) ") ||
+		!strings.Contains(lines[2], " ERR retrying ") ||
+		!strings.Contains(lines[3], " once ") ||
+		!strings.Contains(lines[4], " twice ") {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	lol.Flush()
+	if !strings.Contains(buf.String(), " twice (x2) ") {
+		t.Fatalf("count not written by Flush: %q", buf.String())
+	}
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			lol.SetDedup(false)
+		}
+		l.W.Ln("held")
+	}
+	if !strings.Contains(buf.String(), " held (x2) ") ||
+		strings.Count(buf.String(), " held") != 3 {
+		t.Fatalf("unexpected lines after turning dedup off: %q", buf.String())
+	}
+}
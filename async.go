@@ -0,0 +1,147 @@
+package lol
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// ErrWriterClosed is returned by writes to an asynchronous writer, or to a
+// BatchWriter, after its Close.
+var ErrWriterClosed = errors.New("lol: write to a closed asynchronous writer")
+
+// asyncWriter is the writer returned by NewAsyncWriter.
+type asyncWriter struct {
+	w io.Writer
+	// mx is held for reading by writes and flushes while they queue, and for
+	// writing by Close, so nothing is queued once it has closed the queue.
+	mx     sync.RWMutex
+	closed bool
+	queue  chan asyncItem
+	done   chan struct{}
+	// writing is set while the goroutine writes a line to w, when a line
+	// that cannot be queued may have been logged by w from within that write.
+	writing atomic.Bool
+}
+
+// asyncItem is a line to write, or when flushed is set, a request to report
+// on it once every line queued before it has been written.
+type asyncItem struct {
+	line    []byte
+	flushed chan error
+}
+
+// NewAsyncWriter returns a writer that queues each line for w and writes them
+// from a goroutine of its own, so that logging does not wait on the system
+// calls of a slow writer, such as os.Stderr. It can be given to New like any
+// other writer. Up to bufSize lines are held, and when that many are waiting
+// writes block until there is room, so no line is ever dropped. The function
+// returned with it waits until every line written so far has been written to
+// w, as does the Flush method of the writer, which lets SetFlushLevel and
+// Fatal lines drain it. Close writes the lines still waiting before it
+// returns, and lines written after it fail with ErrWriterClosed. Errors from w
+// go to the handler from SetWriteErrorHandler. A line logged by w from within
+// a write, which would wait on the goroutine that is making that write when the
+// queue is full, goes to os.Stderr instead, and a flush from within one
+// returns at once.
+func NewAsyncWriter(w io.Writer, bufSize int) (io.WriteCloser, func()) {
+	a := &asyncWriter{
+		w:     w,
+		queue: make(chan asyncItem, max(0, bufSize)),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a, func() { _ = a.Flush() }
+}
+
+func (a *asyncWriter) Write(b []byte) (n int, err error) {
+	if !a.mx.TryRLock() {
+		if a.fromRun() {
+			return os.Stderr.Write(b)
+		}
+		a.mx.RLock()
+	}
+	defer a.mx.RUnlock()
+	if a.closed {
+		return 0, ErrWriterClosed
+	}
+	// the caller may reuse b once Write returns
+	item := asyncItem{line: append([]byte(nil), b...)}
+	select {
+	case a.queue <- item:
+		return len(b), nil
+	default:
+	}
+	if a.fromRun() {
+		return os.Stderr.Write(b)
+	}
+	a.queue <- item
+	return len(b), nil
+}
+
+// fromRun is whether a write or flush that would wait was called by w from
+// within the goroutine writing a line to it, which would then wait on itself
+// forever, so that the line goes to os.Stderr instead. The stack is only
+// looked at while the goroutine is writing.
+func (a *asyncWriter) fromRun() bool {
+	return a.writing.Load() && onStack(asyncRunFunc)
+}
+
+// Flush waits until the lines written before it have been written out, and
+// flushes the underlying writer as well when it is a Flusher, returning the
+// error of that.
+func (a *asyncWriter) Flush() (err error) {
+	if !a.mx.TryRLock() {
+		if a.fromRun() {
+			return
+		}
+		a.mx.RLock()
+	}
+	if a.closed {
+		a.mx.RUnlock()
+		return
+	}
+	if a.fromRun() {
+		a.mx.RUnlock()
+		return
+	}
+	flushed := make(chan error, 1)
+	a.queue <- asyncItem{flushed: flushed}
+	a.mx.RUnlock()
+	return <-flushed
+}
+
+// Close writes the lines that are still waiting and stops the goroutine.
+func (a *asyncWriter) Close() error {
+	a.mx.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mx.Unlock()
+	<-a.done
+	return nil
+}
+
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	for item := range a.queue {
+		if item.flushed != nil {
+			var err error
+			if f, ok := a.w.(Flusher); ok {
+				err = f.Flush()
+			}
+			item.flushed <- err
+			continue
+		}
+		a.writing.Store(true)
+		_, err := a.w.Write(item.line)
+		a.writing.Store(false)
+		if err != nil {
+			loadConfig().writeError(err)
+		}
+	}
+}
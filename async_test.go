@@ -0,0 +1,67 @@
+package lol_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestAsyncWriter(t *testing.T) {
+	var buf lockedBuffer
+	w, flush := lol.NewAsyncWriter(&buf, 2)
+	l, _ := lol.New(w)
+	for i := 0; i < 10; i++ {
+		l.I.Ln("queued", i)
+	}
+	flush()
+	if n := strings.Count(buf.String(), " queued "); n != 10 {
+		t.Fatalf("%d of 10 lines written after flush: %q", n, buf.String())
+	}
+	l.I.Ln("last")
+	if err := w.Close(); err != nil ||
+		!strings.Contains(buf.String(), " last ") {
+		t.Fatalf("close did not drain: %v %q", err, buf.String())
+	}
+	if _, err := w.Write([]byte("late\n")); err != lol.ErrWriterClosed {
+		t.Fatalf("write after close returned %v", err)
+	}
+}
+
+// slowWriter takes delay over each write, as a disk or terminal under load
+// does.
+type slowWriter struct{ delay time.Duration }
+
+func (s slowWriter) Write(b []byte) (int, error) {
+	time.Sleep(s.delay)
+	return len(b), nil
+}
+
+// BenchmarkAsyncWriter compares the time a logging goroutine spends on each
+// line written to a slow writer directly and through an asynchronous writer,
+// in bursts that fit in its queue, which is drained between them off the
+// clock.
+func BenchmarkAsyncWriter(b *testing.B) {
+	const burst = 256
+	w := slowWriter{50 * time.Microsecond}
+	b.Run("direct", func(b *testing.B) {
+		l, _ := lol.New(w)
+		for i := 0; i < b.N; i++ {
+			l.I.Ln("line", i)
+		}
+	})
+	b.Run("async", func(b *testing.B) {
+		aw, flush := lol.NewAsyncWriter(w, burst)
+		defer aw.Close()
+		l, _ := lol.New(aw)
+		for i := 0; i < b.N; i++ {
+			if i%burst == 0 {
+				b.StopTimer()
+				flush()
+				b.StartTimer()
+			}
+			l.I.Ln("line", i)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package lol
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// auditGenesis is the prev_hash of the first record of a chain.
+var auditGenesis = strings.Repeat("0", sha256.Size*2)
+
+// auditHashKey introduces the hash that ends every audit record.
+const auditHashKey = `,"hash":"`
+
+// AuditWriter makes a tamper evident audit trail out of the JSON lines of a Log
+// made with NewWithEncoder and JSONEncoder. Each line gets a prev_hash field
+// holding the hash of the line before it and then a hash field holding the
+// SHA-256 of the line up to and including prev_hash, so removing, reordering
+// or changing any line breaks the chain, which VerifyAuditChain checks.
+type AuditWriter struct {
+	mx   sync.Mutex
+	w    io.Writer
+	prev string
+}
+
+// NewAuditWriter returns an AuditWriter starting a new chain on w.
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	return &AuditWriter{w: w, prev: auditGenesis}
+}
+
+// ResumeAuditWriter returns an AuditWriter that carries on the chain read from
+// existing, such as the audit file that w appends to, after verifying it.
+func ResumeAuditWriter(w io.Writer, existing io.Reader) (a *AuditWriter,
+	err error) {
+
+	var last string
+	if last, err = verifyAuditChain(existing); err != nil {
+		return
+	}
+	return &AuditWriter{w: w, prev: last}, nil
+}
+
+// Write chains one JSON object line. The whole line must come in one Write,
+// as it does from a printer.
+func (a *AuditWriter) Write(b []byte) (n int, err error) {
+	line := bytes.TrimRight(b, "\n")
+	if !bytes.HasPrefix(line, []byte("{")) || !bytes.HasSuffix(line,
+		[]byte("}")) {
+		return 0, errors.New("lol: audit records must be JSON objects")
+	}
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	body := make([]byte, 0, len(line)+160)
+	body = append(body, line[:len(line)-1]...)
+	body = append(body, `,"prev_hash":"`...)
+	body = append(body, a.prev...)
+	body = append(body, '"')
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	body = append(body, auditHashKey...)
+	body = append(body, hash...)
+	body = append(body, "\"}\n"...)
+	if _, err = a.w.Write(body); err != nil {
+		return
+	}
+	a.prev = hash
+	return len(b), nil
+}
+
+// VerifyAuditChain reads an audit trail written by AuditWriter and returns an
+// error naming the first line whose hash does not match its contents or whose
+// prev_hash does not match the line before it.
+func VerifyAuditChain(r io.Reader) (err error) {
+	_, err = verifyAuditChain(r)
+	return
+}
+
+func verifyAuditChain(r io.Reader) (last string, err error) {
+	last = auditGenesis
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		var line []byte
+		line, err = br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return last, nil
+		}
+		if err != nil && err != io.EOF {
+			return
+		}
+		if last, err = verifyAuditLine(bytes.TrimRight(line, "\n"),
+			last); err != nil {
+			return "", fmt.Errorf("lol: audit chain broken at line %d: %w",
+				n, err)
+		}
+	}
+}
+
+func verifyAuditLine(line []byte, prev string) (hash string, err error) {
+	i := bytes.LastIndex(line, []byte(auditHashKey))
+	if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return "", errors.New("no hash")
+	}
+	body := line[:i]
+	hash = string(line[i+len(auditHashKey) : len(line)-2])
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", errors.New("hash does not match the record")
+	}
+	var rec struct {
+		PrevHash string `json:"prev_hash"`
+	}
+	if err = json.Unmarshal(append(body[:len(body):len(body)], '}'),
+		&rec); err != nil {
+		return
+	}
+	if rec.PrevHash != prev {
+		return "", errors.New("prev_hash does not match the previous record")
+	}
+	return
+}
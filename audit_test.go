@@ -0,0 +1,44 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestAuditChain(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.NewWithEncoder(lol.NewAuditWriter(&buf), lol.JSONEncoder)
+	l.I.Ln("user bob logged in")
+	l.W.Ln("user bob changed role to admin")
+	l.I.Ln("user bob logged out")
+	if err := lol.VerifyAuditChain(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lol.ParseRecord(
+		bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0]); err != nil {
+		t.Fatalf("audit record no longer parses: %v", err)
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")
+	tampered := strings.Replace(buf.String(), "admin", "guest", 1)
+	deleted := lines[0] + lines[2]
+	for name, trail := range map[string]string{
+		"modified": tampered, "deleted": deleted} {
+		if err := lol.VerifyAuditChain(strings.NewReader(trail)); err == nil {
+			t.Fatalf("%s record not detected", name)
+		}
+	}
+	var more bytes.Buffer
+	a, err := lol.ResumeAuditWriter(&more, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ = lol.NewWithEncoder(a, lol.JSONEncoder)
+	l.I.Ln("resumed")
+	buf.Write(more.Bytes())
+	if err := lol.VerifyAuditChain(&buf); err != nil {
+		t.Fatalf("resumed chain does not verify: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package lol
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BatchWriter gathers the lines written to it and writes them to the writer
+// it wraps together, as returned by NewBatchWriter.
+type BatchWriter struct {
+	w        io.Writer
+	maxDelay time.Duration
+	maxBytes int
+	mx       sync.Mutex
+	buf      []byte
+	// timer flushes buf maxDelay after the first line put in it, and is nil
+	// while buf is empty.
+	timer  *time.Timer
+	closed bool
+}
+
+// NewBatchWriter returns a writer that holds the lines written to it and
+// writes them to w in one Write, once they add up to maxBytes or maxDelay
+// after the first of them, whichever comes first, so that a program logging
+// heavily makes fewer system calls. Lines are written in the order they were
+// written in, from any number of goroutines. With a maxBytes of 0 or less
+// lines are only written on the delay, and with a maxDelay of 0 or less only
+// once enough have been gathered or on Flush. Flush lets SetFlushLevel and
+// Fatal lines write it out straight away. Lines written after Close fail with
+// ErrWriterClosed, and errors of writes on the delay go to the handler from
+// SetWriteErrorHandler.
+func NewBatchWriter(w io.Writer, maxDelay time.Duration,
+	maxBytes int) *BatchWriter {
+
+	return &BatchWriter{w: w, maxDelay: maxDelay, maxBytes: maxBytes}
+}
+
+func (b *BatchWriter) Write(p []byte) (n int, err error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if b.closed {
+		return 0, ErrWriterClosed
+	}
+	b.buf = append(b.buf, p...)
+	if b.maxBytes > 0 && len(b.buf) >= b.maxBytes {
+		return len(p), b.flush()
+	}
+	if b.timer == nil && b.maxDelay > 0 {
+		b.timer = time.AfterFunc(b.maxDelay, func() {
+			if err := b.Flush(); err != nil {
+				loadConfig().writeError(err)
+			}
+		})
+	}
+	return len(p), nil
+}
+
+// Flush writes out the lines that are held, and flushes w as well when it is
+// a Flusher.
+func (b *BatchWriter) Flush() error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if err := b.flush(); err != nil {
+		return err
+	}
+	if f, ok := b.w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// flush writes out buf, with mx held.
+func (b *BatchWriter) flush() (err error) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.buf) == 0 {
+		return
+	}
+	_, err = b.w.Write(b.buf)
+	b.buf = b.buf[:0]
+	return
+}
+
+// Close writes out the lines that are held, after which writes fail.
+func (b *BatchWriter) Close() error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.closed = true
+	return b.flush()
+}
+
+func (b *BatchWriter) wrapped() []io.Writer { return []io.Writer{b.w} }
@@ -0,0 +1,134 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+	"go.uber.org/atomic"
+)
+
+// countingWriter counts the writes made to it.
+type countingWriter struct {
+	mx     sync.Mutex
+	writes int
+	buf    bytes.Buffer
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.writes++
+	return c.buf.Write(b)
+}
+
+func (c *countingWriter) String() string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.buf.String()
+}
+
+func TestBatchWriter(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	cw := &countingWriter{}
+	bw := lol.NewBatchWriter(cw, time.Hour, 1<<20)
+	l, _ := lol.New(bw)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				l.I.F("goroutine %d line %d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if cw.writes != 0 {
+		t.Fatalf("%d writes before the batch was full", cw.writes)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out := cw.String()
+	if cw.writes != 1 || strings.Count(out, "\n") != 100 {
+		t.Fatalf("%d writes of %d lines", cw.writes, strings.Count(out, "\n"))
+	}
+	// lines from each goroutine stay in order
+	last := map[string]int{}
+	for _, m := range regexp.MustCompile(`goroutine (\d) line (\d+)`).
+		FindAllStringSubmatch(out, -1) {
+		i, _ := strconv.Atoi(m[2])
+		if prev, ok := last[m[1]]; ok && i != prev+1 || !ok && i != 0 {
+			t.Fatalf("line %d of goroutine %s out of order", i, m[1])
+		}
+		last[m[1]] = i
+	}
+
+	cw = &countingWriter{}
+	bw = lol.NewBatchWriter(cw, 10*time.Millisecond, 64)
+	_, _ = bw.Write(bytes.Repeat([]byte("x"), 70))
+	if cw.writes != 1 {
+		t.Fatalf("a full batch was not written")
+	}
+	_, _ = bw.Write([]byte("delayed\n"))
+	deadline := time.Now().Add(time.Second)
+	for !strings.HasSuffix(cw.String(), "delayed\n") {
+		if time.Now().After(deadline) {
+			t.Fatalf("batch not written after its delay")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, _ = bw.Write([]byte("closing\n"))
+	if err := bw.Close(); err != nil ||
+		!strings.HasSuffix(cw.String(), "closing\n") {
+		t.Fatalf("Close did not write the batch: %v", err)
+	}
+	if _, err := bw.Write([]byte("late\n")); !errors.Is(err,
+		lol.ErrWriterClosed) {
+		t.Fatalf("write after Close returned %v", err)
+	}
+}
+
+// BenchmarkBatchWriter compares the writes made for lines written as they
+// come with those gathered by a BatchWriter, reported as writes/op.
+func BenchmarkBatchWriter(b *testing.B) {
+	lol.SetLogLevel(lol.Info)
+	run := func(b *testing.B, wrap func(io.Writer) io.Writer) {
+		var writes atomic.Int64
+		w := wrap(writeCounter{&writes})
+		l, _ := lol.New(w)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				l.I.Ln("request handled")
+			}
+		})
+		if f, ok := w.(lol.Flusher); ok {
+			_ = f.Flush()
+		}
+		b.ReportMetric(float64(writes.Load())/float64(b.N), "writes/op")
+	}
+	b.Run("Unbatched", func(b *testing.B) {
+		run(b, func(w io.Writer) io.Writer { return w })
+	})
+	b.Run("Batched", func(b *testing.B) {
+		run(b, func(w io.Writer) io.Writer {
+			return lol.NewBatchWriter(w, 10*time.Millisecond, 64<<10)
+		})
+	})
+}
+
+// writeCounter discards what is written to it, counting the writes.
+type writeCounter struct{ writes *atomic.Int64 }
+
+func (w writeCounter) Write(b []byte) (int, error) {
+	w.writes.Inc()
+	return len(b), nil
+}
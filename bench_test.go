@@ -0,0 +1,36 @@
+package lol_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/mleku/lol"
+	"github.com/mleku/lol/loltest"
+)
+
+func BenchmarkOverhead(b *testing.B) {
+	l, _ := lol.New(io.Discard)
+	loltest.RunOverheadBench(b, l)
+}
+
+// BenchmarkDisabled measures a Trace printer at Info, which only costs the
+// slice of its arguments, and the same line behind Enabled, which costs
+// nothing.
+func BenchmarkDisabled(b *testing.B) {
+	l, _ := lol.New(io.Discard)
+	lol.SetLogLevel(lol.Info)
+	b.Run("F", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.T.F("disabled %d %s", i, "line")
+		}
+	})
+	b.Run("Enabled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if l.T.Enabled() {
+				l.T.F("disabled %d %s", i, "line")
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package lol
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// capture collects the lines of every printer while CaptureLogs runs.
+type capture struct {
+	mx sync.Mutex
+	b  bytes.Buffer
+}
+
+func (c *capture) add(line []byte) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.b.Write(line)
+}
+
+// CaptureLogs runs fn with the log level set to level and returns the lines
+// written meanwhile by every printer, of every Log, which go only to it rather
+// than to their writers, encoded as their printer would encode them. The
+// detail beneath a line, such as a stack, comes as lines of its own. The level
+// and writers are restored afterwards, even when fn panics. As the level and
+// writers are those of the whole program, it is not for tests run in
+// parallel.
+func CaptureLogs(level int, fn func()) (lines []string) {
+	c := &capture{}
+	prev := loadConfig()
+	update(func(cfg *config) { cfg.level, cfg.capture = int32(level), c })
+	defer func() {
+		update(func(cfg *config) {
+			cfg.level, cfg.capture = prev.level, prev.capture
+		})
+	}()
+	fn()
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if s := strings.TrimSuffix(c.b.String(), "\n"); s != "" {
+		lines = strings.Split(s, "\n")
+	}
+	return
+}
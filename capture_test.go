@@ -0,0 +1,34 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestCaptureLogs(t *testing.T) {
+	skipInRelease(t)
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lines := lol.CaptureLogs(lol.Debug, func() {
+		l.D.Ln("debugging")
+		l.T.Ln("too verbose")
+		l.W.F("warned %d", 2)
+	})
+	if len(lines) != 2 || !strings.Contains(lines[0], " DBG debugging ") ||
+		!strings.Contains(lines[1], " WRN warned 2 ") || buf.Len() != 0 {
+		t.Fatalf("captured %q, written %q", lines, buf.String())
+	}
+	func() {
+		defer func() { _ = recover() }()
+		lol.CaptureLogs(lol.Trace, func() { panic("test failed") })
+	}()
+	l.I.Ln("restored")
+	if lol.GetLogLevel() != lol.Info ||
+		!strings.Contains(buf.String(), " restored ") {
+		t.Fatalf("not restored after a panic: %q", buf.String())
+	}
+}
@@ -0,0 +1,150 @@
+package lol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"go.uber.org/atomic"
+)
+
+// Coloring modes, where colorAuto colors only writers that are terminals.
+const (
+	colorAuto = iota
+	colorOn
+	colorOff
+)
+
+// config is everything set through the Set functions. A published config is
+// never changed: setters copy it, change the copy and swap it in, so that each
+// line is written with one consistent set of settings however many setters run
+// alongside it. Entries keep the config they were made with, which encoders
+// render them with.
+type config struct {
+	level int32
+	// color is the coloring mode set with SetColor.
+	color int
+	// jsonMode encodes with JSONEncoder for printers without an encoder.
+	jsonMode bool
+	// fatalDrainTimeout bounds how long a Fatal line waits for a buffering
+	// writer to flush.
+	fatalDrainTimeout time.Duration
+	// flushLevel is the least severe level whose lines flush the writer.
+	flushLevel int32
+	// errIncludeLoc makes the errors returned by Err carry their origin.
+	errIncludeLoc     bool
+	writeErrorHandler func(error)
+	// hooks are from AddHook, replaced rather than appended to in place.
+	hooks    []func(level int, loc, msg string)
+	exitFunc func(code int)
+	// hostname is added to every entry when not empty.
+	hostname      string
+	traceSampler  func(ctx context.Context) (sampled, present bool)
+	correlator    func(ctx context.Context) string
+	timeFormatter func(time.Time) string
+	// noTimestamp leaves timestamps off, from SetTimeFormat("")
+	noTimestamp bool
+	clock       func() time.Time
+	// maskedKeys are lower cased; the map is replaced, never written to.
+	maskedKeys map[string]struct{}
+	// spewConfig is what S dumps with, spew.Config when nil.
+	spewConfig *spew.ConfigState
+	// redactFields are the lower cased names of fields S redacts.
+	redactFields map[string]struct{}
+	// fieldOrder is the rank of each key given to SetFieldOrder.
+	fieldOrder map[string]int
+	// maxMessageLen is the most bytes of a message, unlimited when 0.
+	maxMessageLen int
+	// maxFields is the most fields an entry is encoded with, unlimited when 0.
+	maxFields int
+	// stackTraceLevel is the least severe level that Chk attaches a stack at.
+	stackTraceLevel int32
+	stackSkipErrors []error
+	chkIgnore       []error
+	panicFormatter  func(any) string
+	recoverRepanic  bool
+	// locFormat is how the file of code locations is rendered.
+	locFormat int
+	// locModuleVersion puts the module owning the code in front of locations.
+	locModuleVersion bool
+	piiScrubber      func(string) string
+	jsonOmitEmpty    bool
+	idGenerator      func() string
+	webhookClient    *http.Client
+	debugInternal    bool
+	dedupKey         func(level int, loc, msg string) string
+	// dedup collapses runs of the same line, from SetDedup.
+	dedup bool
+	// subsystemLevels are the levels from SetSubsystemLevel by name.
+	subsystemLevels map[string]int32
+	// fileLevels are the overrides from SetFileLevel, with fileLevelCache
+	// holding the level found for each program counter.
+	fileLevels     []fileLevel
+	fileLevelCache *sync.Map
+	// rateLimit is the most lines a second from one code location, unlimited
+	// when 0.
+	rateLimit int
+	// capture takes the lines of every printer while CaptureLogs runs.
+	capture *capture
+	// sqliteRetention is how many days of rows NewSQLite keeps, all when 0.
+	sqliteRetention int
+	// locFilter is the filter from SetLocFilter, nil when there is none.
+	locFilter *locFilter
+	// redactPatterns are the patterns from SetRedactPatterns.
+	redactPatterns []*regexp.Regexp
+	// levelAliases are the names from SetLevelAlias, in lower case, and their
+	// levels.
+	levelAliases map[string]int
+	// unknownLevel is the level ParseLevel returns for what it cannot parse.
+	unknownLevel int
+	// ringsOnly is set in the copy of a config from forRings.
+	ringsOnly bool
+}
+
+var cfg = atomic.NewPointer(&config{
+	level:             Info,
+	unknownLevel:      Info,
+	fatalDrainTimeout: 2 * time.Second,
+})
+
+// loadConfig returns the current config, which must not be changed.
+func loadConfig() *config {
+	return cfg.Load()
+}
+
+// SetDebugInternal turns on notes about what lol itself is doing, written
+// straight to stderr and never through a Log: lines suppressed by the level,
+// sampling or a filter, callers that could not be found for a code location,
+// as when a skip is too large, and writers that failed.
+func SetDebugInternal(enabled bool) {
+	update(func(c *config) { c.debugInternal = enabled })
+}
+
+// debugf writes a note for SetDebugInternal.
+func (c *config) debugf(format string, a ...interface{}) {
+	if c.debugInternal {
+		fmt.Fprintf(os.Stderr, "lol: "+format+"\n", a...)
+	}
+}
+
+// update publishes a copy of the current config with change applied to it,
+// starting over if another setter published one in the meantime. The
+// subscribers from SubscribeLevel are told when the log level changed.
+func update(change func(c *config)) {
+	for {
+		old := cfg.Load()
+		c := *old
+		change(&c)
+		if cfg.CompareAndSwap(old, &c) {
+			if c.level != old.level {
+				notifyLevel(int(c.level))
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+// TestConcurrentConfig changes settings while other goroutines log, for the
+// race detector to check, and that masking held throughout.
+func TestConcurrentConfig(t *testing.T) {
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	defer func() {
+		lol.SetLogLevel(lol.Info)
+		lol.SetHostnameValue("")
+		lol.SetMaskedKeys()
+		lol.SetMaxFields(0)
+		lol.SetTimeFormatter(nil)
+		lol.SetStackTraceLevel(lol.Off)
+	}()
+	lol.SetMaskedKeys("secret")
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				lol.SetHostnameValue("host-" + strconv.Itoa(n%3))
+				lol.SetMaskedKeys("secret", "key-"+strconv.Itoa(i))
+				lol.SetMaxFields(n % 4)
+				lol.SetLogLevel(lol.Info + n%2)
+				lol.SetStackTraceLevel(lol.Off + n%2*lol.Error)
+				lol.SetTimeFormatter(func(t time.Time) string {
+					return strconv.FormatInt(t.UnixMilli(), 10)
+				})
+			}
+		}(i)
+	}
+	var logs sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		logs.Add(1)
+		go func() {
+			defer logs.Done()
+			for n := 0; n < 200; n++ {
+				l.I.KV("event", "secret", "hunter2", "n", n)
+				l.D.Ln("verbose", n)
+				l.E.Chk(errors.New("failed"))
+			}
+		}()
+	}
+	logs.Wait()
+	close(stop)
+	wg.Wait()
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "hunter2") {
+			t.Fatalf("masked value written: %q", line)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestDebugInternal(t *testing.T) {
+	skipInRelease(t)
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+	lol.SetDebugInternal(true)
+	defer lol.SetDebugInternal(false)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.T.Ln("hidden")
+	failing, _ := lol.New(failingWriter{})
+	failing.I.Ln("lost")
+	far, _ := lol.NewGCP(&buf, 100)
+	far.I.Ln("nowhere")
+	lol.SetLogLevel(-3)
+	l.F.Ln("below off")
+	lol.SetLogLevel(lol.Info)
+	b, _ := os.ReadFile(f.Name())
+	for _, want := range []string{"lol: trace line at ", "config_test.go:",
+		"suppressed, above the log level info", "lol: writer failed: disk full",
+		"lol: no caller 103 frames up", "above the log level off"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%q missing from %q", want, b)
+		}
+	}
+	if strings.Contains(buf.String(), "lol: ") {
+		t.Fatalf("notes went through the log: %q", buf.String())
+	}
+}
+
+// TestEntryConfig checks that an entry is encoded with the settings it was made
+// with, even when they change before the encoder runs.
+func TestEntryConfig(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.SetPIIScrubber(nil)
+	var buf bytes.Buffer
+	l, _ := lol.NewWithEncoder(&buf, func(e *lol.Entry) []byte {
+		lol.SetPIIScrubber(lol.ScrubPII)
+		return lol.JSONEncoder(e)
+	})
+	l.I.Ln("mail bob@example.com")
+	if !strings.Contains(buf.String(), "bob@example.com") {
+		t.Errorf("line used a scrubber set after it was made: %s", buf.String())
+	}
+	buf.Reset()
+	l.I.Ln("mail bob@example.com")
+	if !strings.Contains(buf.String(), "[email]") {
+		t.Errorf("line was not scrubbed: %s", buf.String())
+	}
+}
@@ -0,0 +1,58 @@
+package lol
+
+import (
+	"context"
+)
+
+type (
+	// correlationIDKey is the context key of the ID added by WithContext.
+	correlationIDKey struct{}
+	// logKey is the context key of the Log added by ContextWithLog.
+	logKey struct{}
+)
+
+// WithContext returns ctx with id as its correlation ID, which the printers of
+// the Log from FromContext, and those from LevelPrinter.Ctx, add to each line
+// as correlation_id, so that request scoped code can log with the ID of the
+// request without passing a printer down to it. A key from the correlator set
+// with SetCorrelator takes precedence.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// ContextWithLog returns ctx carrying l, for FromContext to return, such as a
+// Log whose printers were made with LevelPrinter.With.
+func ContextWithLog(ctx context.Context, l *Log) context.Context {
+	return context.WithValue(ctx, logKey{}, l)
+}
+
+// FromContext returns the Log carried by ctx, or the standard Log when there
+// is none, with printers that add the details of ctx to each line as
+// LevelPrinter.Ctx does, including the ID from WithContext. It never returns
+// nil.
+func FromContext(ctx context.Context) *Log {
+	from, _ := ctx.Value(logKey{}).(*Log)
+	if from == nil {
+		from = l
+	}
+	return &Log{
+		F: from.F.Ctx(ctx),
+		E: from.E.Ctx(ctx),
+		W: from.W.Ctx(ctx),
+		I: from.I.Ctx(ctx),
+		D: from.D.Ctx(ctx),
+		T: from.T.Ctx(ctx),
+	}
+}
+
+// correlationID is the correlation ID for ctx, from the correlator set with
+// SetCorrelator or else from WithContext.
+func (c *config) correlationID(ctx context.Context) (id string) {
+	if c.correlator != nil {
+		if id = c.correlator(ctx); id != "" {
+			return
+		}
+	}
+	id, _ = ctx.Value(correlationIDKey{}).(string)
+	return
+}
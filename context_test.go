@@ -0,0 +1,31 @@
+package lol_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestFromContext(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	if lol.FromContext(context.Background()) == nil {
+		t.Fatalf("no Log without one in the context")
+	}
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	base := &lol.Log{F: l.F, E: l.E, W: l.W, I: l.I.With("svc", "api"),
+		D: l.D, T: l.T}
+	ctx := lol.WithContext(lol.ContextWithLog(context.Background(), base),
+		"req-7")
+	handle := func(ctx context.Context) {
+		lol.FromContext(ctx).I.Ln("handled")
+	}
+	handle(ctx)
+	if !strings.Contains(buf.String(),
+		" handled svc=api correlation_id=req-7 ") {
+		t.Fatalf("unexpected line %q", buf.String())
+	}
+}
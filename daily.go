@@ -0,0 +1,172 @@
+package lol
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewDaily returns a Log that writes to a file in dir for each day, from
+// NewDailyWriter without compression or retention, and the writer to close
+// once the Log is done with. skip is as for NewUnixgram.
+func NewDaily(dir, prefix string, skip int) (l *Log, c *Check,
+	closer io.Closer, err error) {
+
+	var w io.WriteCloser
+	if w, err = NewDailyWriter(dir, prefix, false, 0); err != nil {
+		return
+	}
+	l, c = newLog(printer{writer: w, skip: skip})
+	return l, c, w, nil
+}
+
+// NewDailyWriter returns a writer for New that writes to a file in dir for
+// each day, named as prefix-2006-01-02.log for the local date, moving to the
+// file for the next day with the first line written after local midnight.
+// Which file a line goes to depends only on the date it is written at, as
+// given by the clock set with SetClock, so after the clock is set back over
+// midnight lines go to the file of the earlier day again, appended after those
+// already in it. dir is created if it is missing.
+//
+// With gzipRolled the file of a day is compressed in the background, as for
+// NewRotatingWriter, when lines move on to a later day, unless that day was
+// already compressed before the clock was set back. With keepDays above zero
+// the files of days more than keepDays before the current one, compressed or
+// not, are removed when lines move on to a later day. Close syncs and closes
+// the current file and waits for the compression to finish, and errors from
+// compression and removal go to the handler from SetWriteErrorHandler.
+func NewDailyWriter(dir, prefix string, gzipRolled bool,
+	keepDays int) (io.WriteCloser, error) {
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	d := &dailyWriter{dir: dir, prefix: prefix, gzipRolled: gzipRolled,
+		keepDays: keepDays}
+	if err := d.open(loadConfig().now()); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// dailyWriter writes to the file for the date of each write.
+type dailyWriter struct {
+	dir, prefix string
+	gzipRolled  bool
+	keepDays    int
+	mx          sync.Mutex
+	f           *os.File
+	// date is the local midnight starting the day of f.
+	date time.Time
+	// rolling is waited on by Close for the files being gzipped or removed.
+	rolling sync.WaitGroup
+	closed  bool
+}
+
+func (d *dailyWriter) Write(b []byte) (n int, err error) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if d.closed {
+		return 0, ErrWriterClosed
+	}
+	if now := loadConfig().now(); !midnight(now).Equal(d.date) {
+		if err = d.open(now); err != nil {
+			return
+		}
+	}
+	return d.f.Write(b)
+}
+
+// midnight is the local midnight starting the day of t.
+func midnight(t time.Time) time.Time {
+	y, m, day := t.Date()
+	return time.Date(y, m, day, 0, 0, 0, 0, t.Location())
+}
+
+// name is the file of the day starting at date.
+func (d *dailyWriter) name(date time.Time) string {
+	return filepath.Join(d.dir, d.prefix+date.Format("-2006-01-02")+".log")
+}
+
+// open switches to the file for the date of t, closing the one before, and
+// compressing it and removing those past keepDays when t is on a later day.
+func (d *dailyWriter) open(t time.Time) (err error) {
+	date := midnight(t)
+	if date.Before(d.date) {
+		// the file of an earlier day may still be being compressed
+		d.rolling.Wait()
+	}
+	var f *os.File
+	if f, err = os.OpenFile(d.name(date),
+		os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+		return
+	}
+	old, oldDate := d.f, d.date
+	d.f, d.date = f, date
+	if old == nil {
+		return
+	}
+	old.Close()
+	if date.Before(oldDate) {
+		return
+	}
+	compress := d.gzipRolled
+	if _, err := os.Stat(old.Name() + ".gz"); err == nil {
+		// the day was compressed before the clock was set back to it
+		compress = false
+	}
+	d.rolling.Add(1)
+	go func() {
+		defer d.rolling.Done()
+		if compress {
+			if err := gzipFile(old.Name()); err != nil {
+				loadConfig().writeError(err)
+			}
+		}
+		if d.keepDays > 0 {
+			d.prune(date.AddDate(0, 0, -d.keepDays))
+		}
+	}()
+	return
+}
+
+// prune removes the files of the days before cutoff.
+func (d *dailyWriter) prune(cutoff time.Time) {
+	names, err := filepath.Glob(filepath.Join(d.dir, d.prefix+"-*.log*"))
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		stamp := strings.TrimPrefix(filepath.Base(name), d.prefix+"-")
+		stamp, _, _ = strings.Cut(stamp, ".")
+		date, err := time.ParseInLocation("2006-01-02", stamp,
+			cutoff.Location())
+		if err != nil || !date.Before(cutoff) {
+			continue
+		}
+		if err = os.Remove(name); err != nil {
+			loadConfig().writeError(fmt.Errorf("lol: removing %s: %w", name,
+				err))
+		}
+	}
+}
+
+// Close syncs and closes the current file and waits for the rolled files to
+// be compressed and pruned.
+func (d *dailyWriter) Close() (err error) {
+	d.mx.Lock()
+	if !d.closed {
+		d.closed = true
+		err = d.f.Sync()
+		if e := d.f.Close(); err == nil {
+			err = e
+		}
+	}
+	d.mx.Unlock()
+	d.rolling.Wait()
+	return
+}
@@ -0,0 +1,97 @@
+package lol_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestDaily(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	l, _, closer, err := lol.NewDaily(dir, "app", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	l.I.Ln("dated")
+	name := filepath.Join(dir, "app-"+time.Now().Format("2006-01-02")+".log")
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "dated") {
+		t.Fatalf("line missing from %s: %q", name, b)
+	}
+	day := time.Date(2024, 1, 15, 23, 59, 59, 0, time.Local)
+	lol.SetClock(func() time.Time { return day })
+	defer lol.SetClock(nil)
+	l.I.Ln("late")
+	day = day.Add(2 * time.Second)
+	l.I.Ln("early")
+	day = day.Add(-time.Minute)
+	l.I.Ln("set back")
+	for file, want := range map[string]string{
+		"app-2024-01-15.log": "late|set back",
+		"app-2024-01-16.log": "early",
+	} {
+		b, _ := os.ReadFile(filepath.Join(dir, file))
+		for _, w := range strings.Split(want, "|") {
+			if !strings.Contains(string(b), w) {
+				t.Fatalf("%q missing from %s: %q", w, file, b)
+			}
+		}
+	}
+}
+
+func TestDailyWriterRolled(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2024, 1, 13, 12, 0, 0, 0, time.Local)
+	lol.SetClock(func() time.Time { return day })
+	defer lol.SetClock(nil)
+	w, err := lol.NewDailyWriter(dir, "app", true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := lol.New(w)
+	for i := 0; i < 4; i++ {
+		l.I.Ln("day", i)
+		day = day.AddDate(0, 0, 1)
+	}
+	// back to a day that is already compressed
+	day = day.AddDate(0, 0, -2)
+	l.I.Ln("set back")
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	names, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for i, n := range names {
+		names[i] = filepath.Base(n)
+	}
+	want := "app-2024-01-14.log.gz app-2024-01-15.log app-2024-01-15.log.gz " +
+		"app-2024-01-16.log"
+	if got := strings.Join(names, " "); got != want {
+		t.Fatalf("files %s, want %s", got, want)
+	}
+	f, err := os.Open(filepath.Join(dir, "app-2024-01-15.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil || !strings.Contains(string(b), "day 2") {
+		t.Fatalf("unexpected rolled file %q: %v", b, err)
+	}
+	if _, err = w.Write([]byte("late\n")); err != lol.ErrWriterClosed {
+		t.Fatalf("write after close returned %v", err)
+	}
+}
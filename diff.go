@@ -0,0 +1,159 @@
+package lol
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDiffDepth bounds how far Diff follows nested values, which also stops it
+// going round pointer cycles.
+const maxDiffDepth = 32
+
+// Diff compares two values field by field, following pointers, struct fields,
+// map keys and slice elements, and returns one line per difference in path
+// order: "path: old -> new" for a changed value, "+path=new" for an added one
+// and "-path=old" for a removed one.
+func Diff(old, new interface{}) []string {
+	return diffFlat(flatten(old), flatten(new))
+}
+
+func diffFlat(old, new map[string]string) (changes []string) {
+	paths := make([]string, 0, len(old)+len(new))
+	for p := range old {
+		paths = append(paths, p)
+	}
+	for p := range new {
+		if _, ok := old[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		o, inOld := old[p]
+		n, inNew := new[p]
+		switch {
+		case !inOld:
+			changes = append(changes, "+"+p+"="+n)
+		case !inNew:
+			changes = append(changes, "-"+p+"="+o)
+		case o != n:
+			changes = append(changes, p+": "+o+" -> "+n)
+		}
+	}
+	return
+}
+
+// flatten renders every leaf of v under its path, so that a snapshot is not
+// affected by later changes to v.
+func flatten(v interface{}) map[string]string {
+	m := make(map[string]string)
+	flattenValue(m, ".", reflect.ValueOf(v), 0)
+	return m
+}
+
+func flattenValue(m map[string]string, path string, v reflect.Value, depth int) {
+	if depth > maxDiffDepth {
+		m[path] = "..."
+		return
+	}
+	join := func(p string) string {
+		if path == "." {
+			return p
+		}
+		return path + "." + p
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		m[path] = "<nil>"
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			m[path] = "<nil>"
+			return
+		}
+		flattenValue(m, path, v.Elem(), depth+1)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				flattenValue(m, join(t.Field(i).Name), v.Field(i), depth+1)
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		for _, k := range keys {
+			flattenValue(m, join(fmt.Sprint(k.Interface())), v.MapIndex(k),
+				depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			flattenValue(m, fmt.Sprintf("%s[%d]", strings.TrimSuffix(path, "."),
+				i), v.Index(i), depth+1)
+		}
+	default:
+		if v.CanInterface() {
+			m[path] = fmt.Sprint(v.Interface())
+		} else {
+			m[path] = v.String()
+		}
+	}
+}
+
+// WatchDiff logs the value returned by getter at Info under label, then polls
+// it every interval and logs only the fields that changed since the last poll,
+// as found by Diff. Values are redacted as for S1, and nothing is polled or
+// logged while the lines of l.I would not be written. With an interval of zero
+// or less only the first value is logged and nothing is polled. The returned
+// stop ends the polling and waits for it to finish; it is safe to call more
+// than once.
+func (l *Log) WatchDiff(label string, getter func() any,
+	interval time.Duration) (stop func()) {
+
+	c := loadConfig()
+	p, loc := l.I.p, location(c, 1+l.I.p.skip)
+	var pc [1]uintptr
+	runtime.Callers(2+p.skip, pc[:])
+	// values are redacted as for S1 before they are compared, so that a
+	// change to a redacted field shows nowhere
+	redacted := func(c *config, v any) any { return c.redactArgs([]any{v})[0] }
+	v := redacted(c, getter())
+	prev := flatten(v)
+	if c := p.enabledAt(pc[0]); c != nil {
+		p.emitAt(c, loc, label+" "+spewLine(v), "")
+	}
+	if interval <= 0 {
+		return func() {}
+	}
+	done, finished := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			c := p.enabledAt(pc[0])
+			if c == nil {
+				continue
+			}
+			cur := flatten(redacted(c, getter()))
+			if changes := diffFlat(prev, cur); len(changes) > 0 {
+				p.emitAt(c, loc,
+					label+" changed: "+strings.Join(changes, ", "), "")
+			}
+			prev = cur
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-finished
+	}
+}
@@ -0,0 +1,133 @@
+package lol_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+type pool struct {
+	Size  int
+	Conns map[string]bool
+	Tags  []string
+}
+
+func TestDiff(t *testing.T) {
+	old := pool{Size: 2, Conns: map[string]bool{"a": true}, Tags: []string{"x"}}
+	cur := pool{Size: 3, Conns: map[string]bool{"b": true}, Tags: []string{"x"}}
+	want := []string{"-Conns.a=true", "+Conns.b=true", "Size: 2 -> 3"}
+	if got := lol.Diff(&old, &cur); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := lol.Diff(old, old); len(got) != 0 {
+		t.Fatalf("identical values differ: %q", got)
+	}
+}
+
+type lockedBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.String()
+}
+
+func TestWatchDiff(t *testing.T) {
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	var mx sync.Mutex
+	p := pool{Size: 1}
+	stop := l.WatchDiff("pool", func() any {
+		mx.Lock()
+		defer mx.Unlock()
+		return p
+	}, time.Millisecond)
+	mx.Lock()
+	p.Size = 5
+	mx.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "pool changed: Size: 1 -> 5") {
+		if time.Now().After(deadline) {
+			t.Fatalf("change not logged:\n%s", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+	if !strings.Contains(buf.String(), "pool (lol_test.pool){Size:(int)1") {
+		t.Fatalf("initial state not logged:\n%s", buf.String())
+	}
+	if strings.Count(buf.String(), "changed") != 1 {
+		t.Fatalf("unchanged polls were logged:\n%s", buf.String())
+	}
+}
+
+func TestWatchDiffGated(t *testing.T) {
+	lol.SetRedactFields("Secret")
+	defer lol.SetRedactFields()
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	type creds struct{ User, Secret string }
+	var mx sync.Mutex
+	v := creds{"bob", "hunter2"}
+	get := func() any {
+		mx.Lock()
+		defer mx.Unlock()
+		return v
+	}
+	lol.SetLogLevel(lol.Off)
+	stop := l.WatchDiff("creds", get, time.Millisecond)
+	mx.Lock()
+	v.User = "alice"
+	mx.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	lol.SetLogLevel(lol.Info)
+	if buf.String() != "" {
+		t.Fatalf("lines written at Off:\n%s", buf.String())
+	}
+	stop = l.WatchDiff("creds", get, time.Millisecond)
+	mx.Lock()
+	v.Secret = "swordfish"
+	v.User = "carol"
+	mx.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "creds changed:") {
+		if time.Now().After(deadline) {
+			t.Fatalf("change not logged:\n%s", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	if out := buf.String(); strings.Contains(out, "hunter2") ||
+		strings.Contains(out, "swordfish") || !strings.Contains(out, lol.Masked) {
+		t.Fatalf("watched values not redacted:\n%s", out)
+	}
+}
+
+func TestWatchDiffNoInterval(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf lockedBuffer
+	l, _ := lol.New(&buf)
+	polls := 0
+	stop := l.WatchDiff("once", func() any { polls++; return polls }, 0)
+	stop()
+	stop()
+	if polls != 1 || !strings.Contains(buf.String(), " once (int)1 ") {
+		t.Fatalf("%d polls, lines:\n%s", polls, buf.String())
+	}
+}
@@ -0,0 +1,96 @@
+package lol
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LevelEnv is the environment variable that the log level is set from when
+// the package is initialized, if it holds a level that SetLevelFromEnv can
+// parse. It takes precedence over GODEBUG.
+const LevelEnv = "LOL_LOG_LEVEL"
+
+// SetLevelFromEnv sets the log level from the environment variable varName,
+// which holds one of LevelNames in any case, or any start of one, as the first
+// letters are unique, an alias from SetLevelAlias, or the number of a level.
+// Nothing changes when the variable is not set or is empty, so it can be
+// called again, such as on SIGHUP, to pick up a new value, and an error is
+// returned when it holds anything else.
+func SetLevelFromEnv(varName string) (err error) {
+	v := strings.TrimSpace(os.Getenv(varName))
+	if v == "" {
+		return
+	}
+	level, ok := parseLevel(v)
+	if !ok {
+		return fmt.Errorf("lol: unknown level %q in %s", v, varName)
+	}
+	SetLogLevel(level)
+	return
+}
+
+// ParseLevel returns the level named by s, which is one of LevelNames in any
+// case, or any start of one, or the number of a level, as for SetLevelFromEnv,
+// for settings typed in by hand. For anything else it returns the level from
+// SetUnknownLevelDefault, Info unless that is set, so that a typo does not
+// turn on Trace. SetLevelFromEnv and SetSubsystemLevel do not fall back to it
+// but return an error and leave the level as it was.
+func ParseLevel(s string) int {
+	if level, ok := parseLevel(strings.TrimSpace(s)); ok {
+		return level
+	}
+	return loadConfig().unknownLevel
+}
+
+// SetUnknownLevelDefault sets the level ParseLevel returns for a name it
+// does not know, clamped to the range from Off to Trace.
+func SetUnknownLevelDefault(level int) {
+	update(func(c *config) { c.unknownLevel = max(Off, min(Trace, level)) })
+}
+
+// SetLevelAlias adds name as another name for level, in any case, such as
+// critical for Fatal or notice for Info, for the names of levels used
+// elsewhere in an organisation. SetLevelFromEnv, ParseLevel and
+// SetSubsystemLevel take it as they take the names in LevelNames, though
+// unlike those it has to be given whole. The names in LevelNames cannot be
+// aliased, and a level outside Off to Trace removes the alias.
+func SetLevelAlias(name string, level int) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || levelByName(name) >= 0 {
+		return
+	}
+	update(func(c *config) {
+		aliases := make(map[string]int, len(c.levelAliases)+1)
+		for n, l := range c.levelAliases {
+			if n != name {
+				aliases[n] = l
+			}
+		}
+		if level >= Off && level <= Trace {
+			aliases[name] = level
+		}
+		c.levelAliases = aliases
+	})
+}
+
+// parseLevel is the level named by s as for SetLevelFromEnv.
+func parseLevel(s string) (level int, ok bool) {
+	if s == "" {
+		return
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n >= Off && n <= Trace
+	}
+	s = strings.ToLower(s)
+	if level, ok = loadConfig().levelAliases[s]; ok {
+		return
+	}
+	for i, name := range LevelNames {
+		if strings.HasPrefix(name, s) {
+			return i, true
+		}
+	}
+	return
+}
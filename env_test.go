@@ -0,0 +1,84 @@
+package lol_test
+
+import (
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestSetLevelFromEnv(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.SetLogLevel(lol.Info)
+	for _, c := range []struct {
+		value string
+		level int
+		fail  bool
+	}{
+		{"", lol.Info, false},
+		{"DEBUG", lol.Debug, false},
+		{"w", lol.Warn, false},
+		{"6", lol.Trace, false},
+		{"loud", lol.Trace, true},
+		{"9", lol.Trace, true},
+	} {
+		t.Setenv("APP_LEVEL", c.value)
+		if err := lol.SetLevelFromEnv("APP_LEVEL"); (err != nil) != c.fail ||
+			lol.GetLogLevel() != c.level {
+			t.Fatalf("%q: level %d, error %v", c.value, lol.GetLogLevel(),
+				err)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]int{
+		"ERROR": lol.Error, "Warn": lol.Warn, "4": lol.Info, "6": lol.Trace,
+		"0": lol.Off, " debug ": lol.Debug, "unknown": lol.Info, "": lol.Info,
+		"7": lol.Info, "-1": lol.Info,
+	} {
+		if got := lol.ParseLevel(s); got != want {
+			t.Fatalf("%q parsed as %d, not %d", s, got, want)
+		}
+	}
+}
+
+func TestUnknownLevelDefault(t *testing.T) {
+	defer lol.SetUnknownLevelDefault(lol.Info)
+	lol.SetUnknownLevelDefault(lol.Warn)
+	if got := lol.ParseLevel("verbose"); got != lol.Warn {
+		t.Fatalf("unknown level parsed as %d, not %d", got, lol.Warn)
+	}
+	if got := lol.ParseLevel("debug"); got != lol.Debug {
+		t.Fatalf("known level parsed as %d, not %d", got, lol.Debug)
+	}
+	lol.SetUnknownLevelDefault(99)
+	if got := lol.ParseLevel("verbose"); got != lol.Trace {
+		t.Fatalf("default not clamped: %d", got)
+	}
+}
+
+func TestLevelAlias(t *testing.T) {
+	lol.SetLevelAlias("Critical", lol.Fatal)
+	lol.SetLevelAlias("notice", lol.Info)
+	lol.SetLevelAlias("info", lol.Trace)
+	defer func() {
+		lol.SetLevelAlias("critical", -1)
+		lol.SetLevelAlias("notice", -1)
+		_ = lol.SetSubsystemLevel("alias", "")
+	}()
+	for s, want := range map[string]int{
+		"CRITICAL": lol.Fatal, "notice": lol.Info, "info": lol.Info,
+	} {
+		if got := lol.ParseLevel(s); got != want {
+			t.Fatalf("%q parsed as %d, not %d", s, got, want)
+		}
+	}
+	if err := lol.SetSubsystemLevel("alias", "critical"); err != nil ||
+		lol.GetSubsystemLevel("alias") != "fatal" {
+		t.Fatalf("alias not taken by SetSubsystemLevel: %v", err)
+	}
+	lol.SetLevelAlias("critical", -1)
+	if got := lol.ParseLevel("critical"); got != lol.Info {
+		t.Fatalf("removed alias still parsed as %d", got)
+	}
+}
@@ -0,0 +1,45 @@
+package lol
+
+import (
+	"strconv"
+	"sync"
+)
+
+var (
+	errorCodesMx sync.RWMutex
+	errorCodes   = make(map[int]string)
+)
+
+// RegisterErrorCode sets the description logged with code by ErrCode.
+func RegisterErrorCode(code int, desc string) {
+	errorCodesMx.Lock()
+	defer errorCodesMx.Unlock()
+	errorCodes[code] = desc
+}
+
+// codePrefix is how ErrCode introduces the message for code.
+func codePrefix(code int) string {
+	errorCodesMx.RLock()
+	desc, ok := errorCodes[code]
+	errorCodesMx.RUnlock()
+	if !ok {
+		return "[code " + strconv.Itoa(code) + "] "
+	}
+	return "[code " + strconv.Itoa(code) + ": " + desc + "] "
+}
+
+// CodedError is the error returned by ErrCode, which reads as the formatted
+// message and carries the code.
+type CodedError struct {
+	code int
+	err  error
+}
+
+func (c *CodedError) Error() string { return c.err.Error() }
+
+// Code is the numeric code the error was made with.
+func (c *CodedError) Code() int { return c.code }
+
+// Unwrap returns the error made from the format and arguments, so errors
+// wrapped with %w can be reached with errors.Is and errors.As.
+func (c *CodedError) Unwrap() error { return c.err }
@@ -0,0 +1,90 @@
+package lol
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetMaxFields limits how many fields are encoded for each entry, to guard
+// against a loop that keeps adding fields making enormous lines. The first n
+// fields are kept and the rest are replaced with a count of how many were
+// dropped. As this happens when encoding, no printer can get around it. Zero,
+// the default, is unlimited.
+func SetMaxFields(n int) {
+	update(func(c *config) { c.maxFields = n })
+}
+
+// encodeFields returns fields as encoders should render them, masked, scrubbed
+// and cut down to the maximum, with how many were cut.
+func (c *config) encodeFields(fields []Field) (out []Field, dropped int) {
+	if n := c.maxFields; n > 0 && len(fields) > n {
+		dropped = len(fields) - n
+		fields = fields[:n]
+	}
+	fields = maskFields(c.maskedKeys, fields)
+	if c.piiScrubber != nil {
+		fields = scrubFields(c.piiScrubber, fields)
+	}
+	return fields, dropped
+}
+
+// SetFieldOrder makes TextEncoder render the fields with the given keys first,
+// in the order given, such as request_id, and the rest after them sorted by
+// key, so the fields that matter most are always in the same place. Without
+// keys, the default, fields are rendered in the order they were added.
+func SetFieldOrder(keys ...string) {
+	rank := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	if len(keys) == 0 {
+		rank = nil
+	}
+	update(func(c *config) { c.fieldOrder = rank })
+}
+
+// orderFields returns fields in the order set with SetFieldOrder, leaving
+// fields itself untouched.
+func (c *config) orderFields(fields []Field) []Field {
+	rank := c.fieldOrder
+	if rank == nil || len(fields) < 2 {
+		return fields
+	}
+	out := append([]Field(nil), fields...)
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, iok := rank[out[i].Key]
+		rj, jok := rank[out[j].Key]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok || jok:
+			return iok
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// MissingValue is the value given to a key left without one in KVFields.
+const MissingValue = "(missing)"
+
+// KVFields makes fields from alternating keys and values, as in
+// KVFields("user", id, "event", "login"). Keys that are not strings are
+// rendered with fmt, and a trailing key without a value gets MissingValue.
+func KVFields(kvs ...interface{}) (f []Field) {
+	f = make([]Field, 0, (len(kvs)+1)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		k, ok := kvs[i].(string)
+		if !ok {
+			k = fmt.Sprint(kvs[i])
+		}
+		var v interface{} = MissingValue
+		if i+1 < len(kvs) {
+			v = kvs[i+1]
+		}
+		f = append(f, Field{k, v})
+	}
+	return
+}
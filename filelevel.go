@@ -0,0 +1,113 @@
+package lol
+
+import (
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// fileLevel is a level override for the source files matching glob.
+type fileLevel struct {
+	glob  string
+	level int32
+}
+
+// SetFileLevel sets the level for lines logged from the source files matching
+// glob in place of the one from SetLogLevel, such as to trace one noisy file
+// while everything else stays at Info. The glob is as for path.Match and is
+// matched against the whole path of the file as well as each of its trailing
+// parts, so that handler.go, server/*.go and /src/*/server/handler.go all
+// match /src/app/server/handler.go. Where several globs match, the most
+// specific wins, which is the one with the most characters that are not
+// wildcards. Setting a glob again replaces its level, and a level below Off
+// removes it.
+func SetFileLevel(glob string, level int) {
+	update(func(c *config) {
+		levels := make([]fileLevel, 0, len(c.fileLevels)+1)
+		for _, f := range c.fileLevels {
+			if f.glob != glob {
+				levels = append(levels, f)
+			}
+		}
+		if level >= Off {
+			levels = append(levels, fileLevel{glob, int32(level)})
+		}
+		c.fileLevels = levels
+		// the matches found for the old globs no longer hold
+		c.fileLevelCache = &sync.Map{}
+	})
+}
+
+// levelFor is the level that applies to the code of the subsystem name at the
+// caller skip frames up, counted as for location.
+func (c *config) levelFor(name string, skip int) int32 {
+	level := c.subsystemLevel(name)
+	if len(c.fileLevels) == 0 {
+		return level
+	}
+	var pc [1]uintptr
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return level
+	}
+	return c.levelAt(level, pc[0])
+}
+
+// subsystemLevel is the level of the subsystem name, which is the log level
+// unless SetSubsystemLevel set one for it.
+func (c *config) subsystemLevel(name string) int32 {
+	if name != "" {
+		if l, ok := c.subsystemLevels[name]; ok {
+			return l
+		}
+	}
+	return c.level
+}
+
+// levelAt is the level that applies to the code at the program counter pc,
+// which is level unless SetFileLevel set one for its file.
+func (c *config) levelAt(level int32, pc uintptr) int32 {
+	if len(c.fileLevels) == 0 {
+		return level
+	}
+	if l, ok := c.fileLevelCache.Load(pc); ok {
+		if l := l.(int32); l >= Off {
+			return l
+		}
+		return level
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	l := int32(-1)
+	best := -1
+	for _, f := range c.fileLevels {
+		if s := globSpecificity(f.glob); s >= best && fileMatches(f.glob,
+			frame.File) {
+			l, best = f.level, s
+		}
+	}
+	c.fileLevelCache.Store(pc, l)
+	if l >= Off {
+		return l
+	}
+	return level
+}
+
+// fileMatches is whether glob matches file or any part of it that follows a
+// slash.
+func fileMatches(glob, file string) bool {
+	for {
+		if ok, _ := path.Match(glob, file); ok {
+			return true
+		}
+		i := strings.IndexByte(file, '/')
+		if i < 0 {
+			return false
+		}
+		file = file[i+1:]
+	}
+}
+
+// globSpecificity counts the characters of glob that are not wildcards.
+func globSpecificity(glob string) int {
+	return len(glob) - strings.Count(glob, "*") - strings.Count(glob, "?")
+}
@@ -0,0 +1,34 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestFileLevel(t *testing.T) {
+	skipInRelease(t)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetFileLevel("*_test.go", lol.Error)
+	defer lol.SetFileLevel("*_test.go", -1)
+	l.W.Ln("quietened")
+	if buf.Len() != 0 {
+		t.Fatalf("warning written from a file at error: %q", buf.String())
+	}
+	lol.SetFileLevel("filelevel_test.go", lol.Trace)
+	l.T.Ln("traced")
+	if !strings.Contains(buf.String(), "traced") {
+		t.Fatalf("the most specific glob did not win: %q", buf.String())
+	}
+	lol.SetFileLevel("filelevel_test.go", -1)
+	buf.Reset()
+	l.T.Ln("traced")
+	l.E.Ln("error")
+	if strings.Contains(buf.String(), "traced") ||
+		!strings.Contains(buf.String(), "error") {
+		t.Fatalf("removed glob still applied: %q", buf.String())
+	}
+}
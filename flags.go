@@ -0,0 +1,34 @@
+package lol
+
+import (
+	"sort"
+	"strings"
+)
+
+// Flags is a set of named boolean conditions that prints compactly, as
+// [authed,!connected,ready], with the names sorted and unset ones marked with
+// a leading !. Structured encoders render it as a JSON object of booleans.
+//
+//	log.D.Ln("status", lol.Flags{"ready": r, "connected": c, "authed": a})
+type Flags map[string]bool
+
+func (f Flags) String() string {
+	names := make([]string, 0, len(f))
+	for n := range f {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if !f[n] {
+			b.WriteByte('!')
+		}
+		b.WriteString(n)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package lol
+
+import "strings"
+
+// FormatRecord returns the line that the printers of a Log from New write for
+// a line at level, clamped as for Log.At, with the code location loc and the
+// message msg, stamped with the current time, without color or the trailing
+// newline, for custom sinks and hooks that want lines just like those of the
+// printers. The message is redacted and shortened as theirs are.
+func FormatRecord(level int, loc, msg string) string {
+	return formatRecord(TextEncoder, level, loc, msg)
+}
+
+// FormatRecordJSON is FormatRecord for the JSON object that JSONEncoder
+// renders for the line.
+func FormatRecordJSON(level int, loc, msg string) string {
+	return formatRecord(JSONEncoder, level, loc, msg)
+}
+
+// formatRecord renders the line for FormatRecord with enc.
+func formatRecord(enc Encoder, level int, loc, msg string) string {
+	c := loadConfig()
+	e := c.entry(clampLevel(level), loc, c.truncate(c.redactText(msg)))
+	return strings.TrimSuffix(string(enc(e)), "\n")
+}
+
+// entry is a new entry at level with the code location loc and the message
+// text, stamped with the time and hostname from c.
+func (c *config) entry(level int32, loc, text string) *Entry {
+	return &Entry{
+		Time:         c.now(),
+		Level:        LevelSpecs[level].Name,
+		LevelID:      int(level),
+		CodeLocation: loc,
+		Text:         text,
+		Host:         c.hostname,
+		cfg:          c,
+	}
+}
+
+// config is the config to render e with, which is the one it was made with so
+// that a line never mixes the settings from before and after a setter, or the
+// current one for entries made elsewhere.
+func (e *Entry) config() *config {
+	if e.cfg != nil {
+		return e.cfg
+	}
+	return loadConfig()
+}
@@ -0,0 +1,36 @@
+package lol_test
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestFormatRecord(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	at := time.Unix(1700000000, 0)
+	lol.SetClock(func() time.Time { return at })
+	defer lol.SetClock(nil)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	_, file, line, _ := runtime.Caller(0)
+	l.W.Ln("disk low")
+	loc := fmt.Sprint(file, ":", line+1)
+	if got, want := lol.FormatRecord(lol.Warn, loc, "disk low")+"\n",
+		buf.String(); got != want {
+		t.Fatalf("FormatRecord gave %q, the printer wrote %q", got, want)
+	}
+	buf.Reset()
+	j, _ := lol.NewWithEncoder(&buf, lol.JSONEncoder)
+	_, _, line, _ = runtime.Caller(0)
+	j.W.Ln("disk low")
+	loc = fmt.Sprint(file, ":", line+1)
+	if got, want := lol.FormatRecordJSON(lol.Warn, loc, "disk low")+"\n",
+		buf.String(); got != want {
+		t.Fatalf("FormatRecordJSON gave %q, the printer wrote %q", got, want)
+	}
+}
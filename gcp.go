@@ -0,0 +1,102 @@
+package lol
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// gcpSeverity maps each level to its Cloud Logging severity.
+var gcpSeverity = []string{
+	Off:   "DEFAULT",
+	Fatal: "CRITICAL",
+	Error: "ERROR",
+	Warn:  "WARNING",
+	Info:  "INFO",
+	Debug: "DEBUG",
+	Trace: "DEBUG",
+}
+
+// gcpSourceLocation is the key Cloud Logging reads the source location from.
+const gcpSourceLocation = "logging.googleapis.com/sourceLocation"
+
+// gcpKeys are the keys GCPEncoder writes itself, which fields are renamed from.
+var gcpKeys = map[string]struct{}{
+	"severity": {}, "message": {}, "timestamp": {}, gcpSourceLocation: {},
+	"host": {}, "dropped_fields": {},
+}
+
+// NewGCP returns a Log that writes entries to w as the JSON that Google Cloud
+// Logging parses from the output of a container, with GCPEncoder. skip is as
+// for NewUnixgram.
+func NewGCP(w io.Writer, skip int) (l *Log, c *Check) {
+	return newLog(printer{writer: w, encoder: GCPEncoder, skip: skip})
+}
+
+// GCPEncoder renders an entry as one JSON object on a line in the shape of
+// the structured logging of Google Cloud Logging: severity, message, with any
+// detail such as a stack on the lines beneath the text, timestamp, and the
+// file, line and function of the code under
+// logging.googleapis.com/sourceLocation. The host and fields follow as keys
+// of their own, which Cloud Logging keeps in the jsonPayload, with fields
+// named like one of the keys above, such as message, put under fields.message
+// so that they do not replace it.
+func GCPEncoder(e *Entry) []byte {
+	c := e.config()
+	var b bytes.Buffer
+	b.WriteString(`{"severity":`)
+	jsonValue(&b, gcpSeverity[e.LevelID])
+	msg := c.scrubText(e.Text)
+	if e.Detail != "" {
+		msg += "\n" + e.Detail
+	}
+	b.WriteString(`,"message":`)
+	jsonValue(&b, msg)
+	b.WriteString(`,"timestamp":`)
+	jsonValue(&b, e.Time.Format(time.RFC3339Nano))
+	file, line := e.CodeLocation, ""
+	if i := strings.LastIndexByte(file, ':'); i >= 0 {
+		file, line = file[:i], file[i+1:]
+	}
+	// a module version from SetLocModuleVersion is not part of the file
+	if c.locModuleVersion {
+		if i := strings.IndexByte(file, ' '); i >= 0 &&
+			strings.Contains(file[:i], "@") {
+			file = file[i+1:]
+		}
+	}
+	fmt.Fprintf(&b, `,%q:{"file":`, gcpSourceLocation)
+	jsonValue(&b, file)
+	b.WriteString(`,"line":`)
+	jsonValue(&b, line)
+	if e.Function != "" {
+		b.WriteString(`,"function":`)
+		jsonValue(&b, e.Function)
+	}
+	b.WriteByte('}')
+	if e.Host != "" {
+		b.WriteString(`,"host":`)
+		jsonValue(&b, e.Host)
+	}
+	fields, dropped := c.encodeFields(e.Fields)
+	for _, f := range fields {
+		b.WriteByte(',')
+		if _, ok := gcpKeys[f.Key]; ok {
+			f.Key = "fields." + f.Key
+		}
+		jsonValue(&b, f.Key)
+		b.WriteByte(':')
+		if nested, ok := f.Value.([]Field); ok {
+			jsonFields(&b, nested)
+			continue
+		}
+		jsonValue(&b, f.Value)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&b, `,"dropped_fields":%d`, dropped)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
@@ -0,0 +1,62 @@
+package lol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestGCP(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.NewGCP(&buf, 0)
+	l.W.KV("disk low", "free", 3)
+	var got struct {
+		Severity  string `json:"severity"`
+		Message   string `json:"message"`
+		Timestamp string `json:"timestamp"`
+		Source    struct {
+			File     string `json:"file"`
+			Line     string `json:"line"`
+			Function string `json:"function"`
+		} `json:"logging.googleapis.com/sourceLocation"`
+		Free int `json:"free"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v in %s", err, buf.String())
+	}
+	if _, err := time.Parse(time.RFC3339Nano, got.Timestamp); err != nil ||
+		got.Severity != "WARNING" || got.Message != "disk low" || got.Free != 3 {
+		t.Fatalf("unexpected entry %s", buf.String())
+	}
+	if !strings.HasSuffix(got.Source.File, "gcp_test.go") ||
+		got.Source.Line == "" ||
+		got.Source.Function != "github.com/mleku/lol_test.TestGCP" {
+		t.Fatalf("unexpected source location %+v", got.Source)
+	}
+}
+
+func TestGCPKeys(t *testing.T) {
+	e := &lol.Entry{
+		Time:         time.Now(),
+		LevelID:      lol.Info,
+		CodeLocation: "/src/my app/main.go:7",
+		Text:         "started",
+		Fields:       []lol.Field{{"message", "other"}, {"severity", 1}},
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(lol.GCPEncoder(e), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["message"] != "started" || got["severity"] != "INFO" ||
+		got["fields.message"] != "other" || got["fields.severity"] != 1.0 {
+		t.Fatalf("fields replaced keys of the entry: %v", got)
+	}
+	src, _ := got["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	if src["file"] != "/src/my app/main.go" {
+		t.Fatalf("file of %q is %v", e.CodeLocation, src["file"])
+	}
+}
@@ -0,0 +1,38 @@
+package lol
+
+// AddHook adds fn to the functions called with each line that is within the
+// log level, such as to forward errors to an alerting service or to check in
+// tests what was logged. Hooks are called in the order they were added, on
+// the goroutine that logged the line and before it is written, with its level,
+// code location and message. A hook that panics is recovered from, and the
+// line and the other hooks go ahead.
+func AddHook(fn func(level int, loc, msg string)) {
+	if fn == nil {
+		return
+	}
+	update(func(c *config) {
+		c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], fn)
+	})
+}
+
+// RemoveHooks removes all the hooks added with AddHook.
+func RemoveHooks() {
+	update(func(c *config) { c.hooks = nil })
+}
+
+// runHooks calls the hooks with e.
+func (c *config) runHooks(e *Entry) {
+	for _, fn := range c.hooks {
+		c.runHook(fn, e)
+	}
+}
+
+func (c *config) runHook(fn func(level int, loc, msg string), e *Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.debugf("hook panicked for the line at %s: %v", e.CodeLocation,
+				r)
+		}
+	}()
+	fn(e.LevelID, e.CodeLocation, e.Text)
+}
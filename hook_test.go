@@ -0,0 +1,31 @@
+package lol_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestAddHook(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.RemoveHooks()
+	var calls []string
+	lol.AddHook(func(level int, loc, msg string) {
+		calls = append(calls, "first "+lol.LevelNames[level]+" "+msg)
+	})
+	lol.AddHook(func(level int, loc, msg string) { panic("broken hook") })
+	lol.AddHook(func(level int, loc, msg string) {
+		if !strings.Contains(loc, "hook_test.go:") {
+			t.Errorf("unexpected location %q", loc)
+		}
+		calls = append(calls, "third "+msg)
+	})
+	l, _ := lol.New(io.Discard)
+	_ = l.E.Err("failed %d", 1)
+	l.D.Ln("below the level")
+	if strings.Join(calls, "|") != "first error failed 1|third failed 1" {
+		t.Fatalf("unexpected calls %q", calls)
+	}
+}
@@ -0,0 +1,98 @@
+package lol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JournaldSocket is where systemd-journald listens for the native protocol.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// journaldPriority maps each level to its syslog priority.
+var journaldPriority = []int{
+	Off:   7,
+	Fatal: 2,
+	Error: 3,
+	Warn:  4,
+	Info:  6,
+	Debug: 7,
+	Trace: 7,
+}
+
+// NewJournald returns a Log that sends entries to journald with the native
+// protocol, so the level, code location and fields arrive as journal fields
+// rather than text. skip is as for NewUnixgram.
+func NewJournald(skip int) (l *Log, c *Check, err error) {
+	var w *unixgramWriter
+	if w, err = dialUnixgram(JournaldSocket); err != nil {
+		return
+	}
+	l, c = newLog(printer{writer: w, encoder: JournaldEncoder, skip: skip})
+	return
+}
+
+// JournaldEncoder renders an entry as one message of the journald native
+// protocol. Fields become upper cased journal fields, and any detail such as a
+// stack is appended to MESSAGE on the lines beneath the text.
+func JournaldEncoder(e *Entry) []byte {
+	c := e.config()
+	var b bytes.Buffer
+	journaldField(&b, "PRIORITY", fmt.Sprint(journaldPriority[e.LevelID]))
+	msg := c.scrubText(e.Text)
+	if e.Detail != "" {
+		msg += "\n" + e.Detail
+	}
+	journaldField(&b, "MESSAGE", msg)
+	if i := strings.LastIndexByte(e.CodeLocation, ':'); i >= 0 {
+		journaldField(&b, "CODE_FILE", e.CodeLocation[:i])
+		journaldField(&b, "CODE_LINE", e.CodeLocation[i+1:])
+	}
+	journaldField(&b, "SYSLOG_IDENTIFIER", filepath.Base(os.Args[0]))
+	fields, dropped := c.encodeFields(e.Fields)
+	for _, f := range fields {
+		journaldField(&b, journaldKey(f.Key), fmt.Sprint(f.Value))
+	}
+	if dropped > 0 {
+		journaldField(&b, "DROPPED_FIELDS", fmt.Sprint(dropped))
+	}
+	return b.Bytes()
+}
+
+// journaldField appends one field. Values containing a newline cannot use the
+// KEY=value form, so they are sent as the key on its own line followed by the
+// value length as a little endian uint64 and then the value itself.
+func journaldField(b *bytes.Buffer, key, value string) {
+	if !strings.ContainsRune(value, '\n') {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteString(key)
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldKey makes a field name journald accepts: upper case letters, digits
+// and underscores, not starting with an underscore, which is reserved for
+// fields journald sets itself.
+func journaldKey(key string) string {
+	k := []byte(strings.ToUpper(key))
+	for i, c := range k {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			k[i] = '_'
+		}
+	}
+	s := strings.TrimLeft(string(k), "_")
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		s = "F_" + s
+	}
+	return s
+}
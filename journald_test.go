@@ -0,0 +1,30 @@
+package lol_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestJournaldEncoder(t *testing.T) {
+	got := lol.JournaldEncoder(&lol.Entry{
+		LevelID:      lol.Error,
+		CodeLocation: "/src/main.go:42",
+		Text:         "first\nsecond",
+		Fields:       []lol.Field{{"request-id", 7}},
+	})
+	multi := []byte("first\nsecond")
+	var want bytes.Buffer
+	want.WriteString("PRIORITY=3\nMESSAGE\n")
+	_ = binary.Write(&want, binary.LittleEndian, uint64(len(multi)))
+	want.Write(multi)
+	want.WriteString("\nCODE_FILE=/src/main.go\nCODE_LINE=42\n")
+	if !bytes.HasPrefix(got, want.Bytes()) {
+		t.Fatalf("got\n%q\nwant prefix\n%q", got, want.Bytes())
+	}
+	if !bytes.HasSuffix(got, []byte("\nREQUEST_ID=7\n")) {
+		t.Fatalf("field not upper cased at the end of %q", got)
+	}
+}
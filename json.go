@@ -0,0 +1,261 @@
+package lol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// JSONEncoder renders an entry as one JSON object on a line, with the keys
+// time, level, loc, msg, host when set, fields, holding the fields in order,
+// dropped_fields when SetMaxFields cut some, and detail when set. With
+// SetJSONOmitEmpty, loc, msg and fields are left out too when empty, as are
+// empty field values. ParseRecord reads these lines back.
+func JSONEncoder(e *Entry) []byte {
+	c := e.config()
+	omit := c.jsonOmitEmpty
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	jsonValue(&b, e.Time.Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	jsonValue(&b, LevelNames[e.LevelID])
+	if !omit || e.CodeLocation != "" {
+		b.WriteString(`,"loc":`)
+		jsonValue(&b, e.CodeLocation)
+	}
+	if msg := c.scrubText(e.Text); !omit || msg != "" {
+		b.WriteString(`,"msg":`)
+		jsonValue(&b, msg)
+	}
+	if e.Host != "" {
+		b.WriteString(`,"host":`)
+		jsonValue(&b, e.Host)
+	}
+	fields, dropped := c.encodeFields(e.Fields)
+	if omit {
+		fields = omitEmpty(fields)
+	}
+	if !omit || len(fields) > 0 {
+		b.WriteString(`,"fields":`)
+		jsonFields(&b, fields)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&b, `,"dropped_fields":%d`, dropped)
+	}
+	if e.Detail != "" {
+		b.WriteString(`,"detail":`)
+		jsonValue(&b, e.Detail)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// SetJSONOmitEmpty makes JSONEncoder leave out the loc, msg and fields keys
+// when they are empty, and fields whose values are empty, which are nil, empty
+// strings and empty maps, slices and arrays, to keep records small at high
+// volume. The time and level are always written, so records still parse.
+func SetJSONOmitEmpty(omit bool) {
+	update(func(c *config) { c.jsonOmitEmpty = omit })
+}
+
+// omitEmpty returns fields without those whose values are empty, including
+// inside fields that hold fields.
+func omitEmpty(fields []Field) (out []Field) {
+	for _, f := range fields {
+		if nested, ok := f.Value.([]Field); ok {
+			f.Value = omitEmpty(nested)
+		}
+		if !emptyValue(f.Value) {
+			out = append(out, f)
+		}
+	}
+	return
+}
+
+func emptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch r := reflect.ValueOf(v); r.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice, reflect.Array:
+		return r.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return r.IsNil()
+	}
+	return false
+}
+
+// jsonFields writes fields as an object, keeping their order.
+func jsonFields(b *bytes.Buffer, fields []Field) {
+	b.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		jsonValue(b, f.Key)
+		b.WriteByte(':')
+		if nested, ok := f.Value.([]Field); ok {
+			jsonFields(b, nested)
+			continue
+		}
+		jsonValue(b, f.Value)
+	}
+	b.WriteByte('}')
+}
+
+// jsonValue writes v as JSON, or as the JSON string of its fmt rendering if it
+// cannot be marshalled, such as a channel or a func.
+func jsonValue(b *bytes.Buffer, v interface{}) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		j, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(j)
+}
+
+// ParseRecord reads an entry back from a line written by JSONEncoder. Field
+// values come back as their JSON types, with numbers as json.Number and objects
+// as map[string]interface{}, and the order of the fields is kept.
+func ParseRecord(line []byte) (e Entry, err error) {
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err = expectDelim(d, '{'); err != nil {
+		return
+	}
+	var seenLevel bool
+	for d.More() {
+		var key string
+		if key, err = stringToken(d); err != nil {
+			return
+		}
+		switch key {
+		case "time":
+			var s string
+			if s, err = stringToken(d); err != nil {
+				return
+			}
+			if e.Time, err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return
+			}
+		case "level":
+			var s string
+			if s, err = stringToken(d); err != nil {
+				return
+			}
+			if e.LevelID = levelByName(s); e.LevelID < 0 {
+				err = fmt.Errorf("lol: unknown level %q", s)
+				return
+			}
+			e.Level = LevelSpecs[e.LevelID].Name
+			seenLevel = true
+		case "loc":
+			e.CodeLocation, err = stringToken(d)
+		case "msg":
+			e.Text, err = stringToken(d)
+		case "host":
+			e.Host, err = stringToken(d)
+		case "detail":
+			e.Detail, err = stringToken(d)
+		case "fields":
+			e.Fields, err = parseFields(d)
+		default:
+			var skip interface{}
+			err = d.Decode(&skip)
+		}
+		if err != nil {
+			return
+		}
+	}
+	if err = expectDelim(d, '}'); err != nil {
+		return
+	}
+	if !seenLevel {
+		err = errors.New("lol: record has no level")
+	}
+	return
+}
+
+func parseFields(d *json.Decoder) (fields []Field, err error) {
+	if err = expectDelim(d, '{'); err != nil {
+		return
+	}
+	for d.More() {
+		var f Field
+		if f.Key, err = stringToken(d); err != nil {
+			return
+		}
+		if err = d.Decode(&f.Value); err != nil {
+			return
+		}
+		fields = append(fields, f)
+	}
+	err = expectDelim(d, '}')
+	return
+}
+
+func expectDelim(d *json.Decoder, want json.Delim) error {
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return fmt.Errorf("lol: expected %v in record, found %v", want, t)
+	}
+	return nil
+}
+
+func stringToken(d *json.Decoder) (s string, err error) {
+	var t json.Token
+	if t, err = d.Token(); err != nil {
+		return
+	}
+	var ok bool
+	if s, ok = t.(string); !ok {
+		err = fmt.Errorf("lol: expected a string in record, found %v", t)
+	}
+	return
+}
+
+// levelByName returns the level with the given entry of LevelNames, or -1.
+func levelByName(name string) int {
+	for i, n := range LevelNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// RecordScanner reads entries from a stream of lines written by JSONEncoder.
+type RecordScanner struct {
+	r *bufio.Reader
+}
+
+// NewRecordScanner returns a RecordScanner reading from r.
+func NewRecordScanner(r io.Reader) *RecordScanner {
+	return &RecordScanner{r: bufio.NewReader(r)}
+}
+
+// Next returns the entry on the next non-empty line, or io.EOF at the end of
+// the stream. A malformed line returns its parse error, and the following call
+// carries on with the line after it.
+func (s *RecordScanner) Next() (e Entry, err error) {
+	for {
+		var line []byte
+		line, err = s.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			return ParseRecord(line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package lol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	in := lol.Entry{
+		Time:         time.Date(2024, 1, 15, 10, 4, 5, 123456789, time.UTC),
+		Level:        lol.LevelSpecs[lol.Warn].Name,
+		LevelID:      lol.Warn,
+		CodeLocation: "/src/main.go:42",
+		Text:         "disk \"low\"",
+		Fields:       []lol.Field{{"free", 12}, {"dev", "sda"}},
+		Detail:       "main.main\n\t/src/main.go:42",
+	}
+	out, err := lol.ParseRecord(lol.JSONEncoder(&in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Time.Equal(in.Time) || out.LevelID != in.LevelID ||
+		out.Level != in.Level || out.CodeLocation != in.CodeLocation ||
+		out.Text != in.Text || out.Detail != in.Detail {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+	if len(out.Fields) != 2 || out.Fields[0].Key != "free" ||
+		out.Fields[0].Value != json.Number("12") ||
+		out.Fields[1].Value != "sda" {
+		t.Fatalf("fields did not round trip in order: %+v", out.Fields)
+	}
+}
+
+func TestRecordScanner(t *testing.T) {
+	var buf bytes.Buffer
+	e := &lol.Entry{LevelID: lol.Info, Text: "first"}
+	buf.Write(lol.JSONEncoder(e))
+	buf.WriteString("{not json\n\n")
+	e.Text = "second"
+	buf.Write(lol.JSONEncoder(e))
+	s := lol.NewRecordScanner(&buf)
+	if r, err := s.Next(); err != nil || r.Text != "first" {
+		t.Fatalf("got %+v, %v", r, err)
+	}
+	if _, err := s.Next(); err == nil {
+		t.Fatalf("malformed line parsed")
+	}
+	if r, err := s.Next(); err != nil || r.Text != "second" {
+		t.Fatalf("scanning did not continue after a bad line: %+v, %v", r, err)
+	}
+	if _, err := s.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF at the end, got %v", err)
+	}
+}
+
+func TestNewWithEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.NewWithEncoder(&buf, lol.JSONEncoder)
+	l.E.Ln("as json")
+	r, err := lol.ParseRecord(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.LevelID != lol.Error || r.Text != "as json" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestJSONOmitEmpty(t *testing.T) {
+	lol.SetJSONOmitEmpty(true)
+	defer lol.SetJSONOmitEmpty(false)
+	e := &lol.Entry{
+		Time:    time.Unix(0, 0).UTC(),
+		LevelID: lol.Info,
+		Fields: []lol.Field{{"empty", ""}, {"none", nil},
+			{"m", map[string]int{}}, {"n", 0},
+			{"group", []lol.Field{{"a", ""}}}},
+	}
+	got := string(lol.JSONEncoder(e))
+	if want := `{"time":"1970-01-01T00:00:00Z","level":"info",` +
+		`"fields":{"n":0}}` + "\n"; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	e.Fields = nil
+	got = string(lol.JSONEncoder(e))
+	if _, err := lol.ParseRecord([]byte(got)); err != nil ||
+		got != `{"time":"1970-01-01T00:00:00Z","level":"info"}`+"\n" {
+		t.Fatalf("unexpected record %s: %v", got, err)
+	}
+}
+
+func TestJSONMode(t *testing.T) {
+	var buf bytes.Buffer
+	l, chk := lol.New(&buf)
+	lol.SetJSONMode(true)
+	defer lol.SetJSONMode(false)
+	l.I.F("count %d", 3)
+	chk.E(io.EOF)
+	lol.GetNullPrinter().Ln("silent")
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("unexpected lines %q", buf.String())
+	}
+	var info, chkd struct {
+		Level  string            `json:"level"`
+		Loc    string            `json:"loc"`
+		Msg    string            `json:"msg"`
+		Fields map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(lines[0], &info); err != nil ||
+		info.Level != "info" || info.Msg != "count 3" ||
+		!bytes.Contains([]byte(info.Loc), []byte("json_test.go:")) {
+		t.Fatalf("unexpected record %s: %v", lines[0], err)
+	}
+	if err := json.Unmarshal(lines[1], &chkd); err != nil ||
+		chkd.Level != "error" || chkd.Fields["err"] != "EOF" {
+		t.Fatalf("unexpected record %s: %v", lines[1], err)
+	}
+	lol.SetJSONMode(false)
+	buf.Reset()
+	chk.E(io.EOF)
+	if bytes.Contains(buf.Bytes(), []byte("err=")) {
+		t.Fatalf("err field added in text mode: %q", buf.String())
+	}
+}
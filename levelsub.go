@@ -0,0 +1,50 @@
+package lol
+
+import "sync"
+
+// levelSubs holds the channels from SubscribeLevel, by the receive-only form
+// that was handed out.
+var levelSubs struct {
+	sync.Mutex
+	chans map[<-chan int]chan int
+}
+
+// SubscribeLevel returns a channel that gets the new log level each time it
+// is changed, by SetLogLevel, SetLevelFromEnv or the signals from
+// InstallSignalHandlers, such as for a display of the current verbosity. Each
+// call returns a channel of its own. A change is dropped for a subscriber that
+// has not yet received the one before, so that setting the level never waits
+// on one; the receiver can read GetLogLevel for the latest.
+func SubscribeLevel() <-chan int {
+	ch := make(chan int, 1)
+	levelSubs.Lock()
+	defer levelSubs.Unlock()
+	if levelSubs.chans == nil {
+		levelSubs.chans = make(map[<-chan int]chan int)
+	}
+	levelSubs.chans[ch] = ch
+	return ch
+}
+
+// UnsubscribeLevel stops changes of the log level going to ch, from
+// SubscribeLevel, and closes it.
+func UnsubscribeLevel(ch <-chan int) {
+	levelSubs.Lock()
+	defer levelSubs.Unlock()
+	if c, ok := levelSubs.chans[ch]; ok {
+		delete(levelSubs.chans, ch)
+		close(c)
+	}
+}
+
+// notifyLevel sends level to each subscriber that has room for it.
+func notifyLevel(level int) {
+	levelSubs.Lock()
+	defer levelSubs.Unlock()
+	for _, c := range levelSubs.chans {
+		select {
+		case c <- level:
+		default:
+		}
+	}
+}
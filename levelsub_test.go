@@ -0,0 +1,34 @@
+package lol_test
+
+import (
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestSubscribeLevel(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	a, b := lol.SubscribeLevel(), lol.SubscribeLevel()
+	defer lol.UnsubscribeLevel(b)
+	lol.SetLogLevel(lol.Debug)
+	for _, ch := range []<-chan int{a, b} {
+		if got := <-ch; got != lol.Debug {
+			t.Fatalf("got level %d, want %d", got, lol.Debug)
+		}
+	}
+	// b is not read, so the second change is dropped for it
+	lol.SetLogLevel(lol.Trace)
+	lol.SetLogLevel(lol.Warn)
+	if got := <-a; got != lol.Trace {
+		t.Fatalf("got level %d, want %d", got, lol.Trace)
+	}
+	lol.SetLogLevel(lol.Warn)
+	lol.UnsubscribeLevel(a)
+	if _, ok := <-a; ok {
+		t.Fatalf("a level was sent for an unchanged level")
+	}
+	lol.SetLogLevel(lol.Info)
+	if got := <-b; got != lol.Trace {
+		t.Fatalf("got level %d, want %d", got, lol.Trace)
+	}
+}
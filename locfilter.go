@@ -0,0 +1,75 @@
+package lol
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// locFilter is the filter of code locations from SetLocFilter.
+type locFilter struct {
+	include, exclude []string
+	// allowed holds whether the location of each locFilterKey is allowed.
+	allowed sync.Map
+}
+
+// locFilterKey is a program counter along with the settings its code
+// location was rendered with.
+type locFilterKey struct {
+	pc            uintptr
+	format        int
+	moduleVersion bool
+}
+
+// SetLocFilter limits the lines written to those whose code location, as
+// GetLoc renders it, contains one of include, or any location when include is
+// empty, and none of exclude, such as to trace only the files under
+// internal/relay/ by including that. Lines still have to be within the log
+// level before they are filtered. Passing no filters at all removes the
+// filter.
+func SetLocFilter(include, exclude []string) {
+	var f *locFilter
+	if len(include) > 0 || len(exclude) > 0 {
+		f = &locFilter{
+			include: append([]string(nil), include...),
+			exclude: append([]string(nil), exclude...),
+		}
+	}
+	update(func(c *config) { c.locFilter = f })
+}
+
+// allows is whether the filter lets through lines from the caller skip frames
+// up, counted as for location, with its location rendered with the settings
+// in c.
+func (f *locFilter) allows(c *config, skip int) bool {
+	var pc [1]uintptr
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return true
+	}
+	return f.allowsAt(c, pc[0])
+}
+
+// allowsAt is allows for the code at the program counter pc.
+func (f *locFilter) allowsAt(c *config, pc uintptr) bool {
+	key := locFilterKey{pc, c.locFormat, c.locModuleVersion}
+	if ok, found := f.allowed.Load(key); found {
+		return ok.(bool)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	loc := locationAt(c, frame.PC, frame.File, frame.Line)
+	ok := len(f.include) == 0
+	for _, s := range f.include {
+		if strings.Contains(loc, s) {
+			ok = true
+			break
+		}
+	}
+	for _, s := range f.exclude {
+		if strings.Contains(loc, s) {
+			ok = false
+			break
+		}
+	}
+	f.allowed.Store(key, ok)
+	return ok
+}
@@ -0,0 +1,34 @@
+package lol_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestLocFilter(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.SetLocFilter(nil, nil)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLocFilter([]string{"internal/relay/"}, nil)
+	l.I.Ln("outside the filter")
+	if buf.Len() != 0 {
+		t.Fatalf("line outside the include written: %q", buf.String())
+	}
+	lol.SetLocFilter([]string{"locfilter_test.go"}, []string{":22"})
+	l.I.Ln("kept")
+	l.I.Ln("excluded")
+	l.D.Ln("above the level")
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 1 ||
+		!bytes.Contains(buf.Bytes(), []byte(" kept ")) {
+		t.Fatalf("unexpected lines %q", buf.String())
+	}
+	buf.Reset()
+	lol.SetLocFilter(nil, nil)
+	l.I.Ln("unfiltered")
+	if buf.Len() == 0 {
+		t.Fatalf("line not written once the filter was removed")
+	}
+}
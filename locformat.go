@@ -0,0 +1,38 @@
+package lol
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// Ways of rendering the file of a code location for SetLocFormat.
+const (
+	// LocFull is the whole path of the file, as /src/app/server/handler.go.
+	LocFull = iota
+	// LocPackage is the import path of the package followed by the name of
+	// the file, as example.com/app/server/handler.go.
+	LocPackage
+	// LocShort is the name of the file alone, as handler.go.
+	LocShort
+)
+
+// SetLocFormat sets how the file of code locations is rendered, in lines and
+// by GetLoc, as LocFull, the default, LocPackage or LocShort. The line number
+// follows it either way.
+func SetLocFormat(format int) {
+	update(func(c *config) { c.locFormat = format })
+}
+
+// locFile renders file, which the program counter pc is in, as format has it.
+func locFile(format int, pc uintptr, file string) string {
+	switch format {
+	case LocShort:
+		return filepath.Base(file)
+	case LocPackage:
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			return packagePath(fn.Name()) + "/" + filepath.Base(file)
+		}
+		return filepath.Base(filepath.Dir(file)) + "/" + filepath.Base(file)
+	}
+	return file
+}
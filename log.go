@@ -1,12 +1,17 @@
 package lol
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/gookit/color"
@@ -44,7 +49,7 @@ func init() {
 	default:
 		SetLogLevel(Info)
 	}
-
+	_ = SetLevelFromEnv(LevelEnv)
 }
 
 const (
@@ -70,18 +75,66 @@ type (
 	// C accepts a function so that the extra computation can be avoided if it is
 	// not being viewed
 	C func(closure func() string)
+	// CF is F for a format and arguments returned by closure, which is only
+	// called if the line is printed
+	CF func(closure func() (format string, a []interface{}))
+	// CS is S for the value returned by closure, which is only called if the
+	// line is printed
+	CS func(closure func() interface{})
 	// Chk is a shortcut for printing if there is an error, or returning true
 	Chk func(e error) bool
+	// ChkE is Chk for returning the error, which is nil when e is nil, and
+	// otherwise wraps e with the code location of the caller in front
+	ChkE func(e error) error
 	// Err is a pass-through function that uses fmt.Errorf to construct an error
-	// and returns the error after printing it to the log
-	Err          func(format string, a ...interface{}) error
+	// and returns the error after printing it to the log, as a *LogError
+	Err func(format string, a ...interface{}) error
+	// Errw is Err for an error that wraps another, reading as the formatted
+	// message followed by a colon and the wrapped error, and unwrapping to it
+	Errw func(wrapped error, format string, a ...interface{}) error
+	// Stack prints like Ln followed by the stack of the caller, with runs of
+	// identical frames collapsed into one
+	Stack func(a ...interface{})
+	// S1 prints a compact spew of each item, keeping the whole entry on one line
+	S1 func(a ...interface{})
+	// Retry prints that an attempt failed with err and how long until the next,
+	// and does nothing when err is nil
+	Retry func(attempt int, wait time.Duration, err error)
+	// Raw1 prints an already formatted string as the message, without going
+	// through JoinStrings or fmt
+	Raw1 func(s string)
+	// ErrCode is Err for an error with a numeric code, printed with the
+	// description registered for the code by RegisterErrorCode
+	ErrCode func(code int, format string, a ...interface{}) error
+	// Rate prints the counter of the given name, as added to with Count, with
+	// its rate per second since the last Rate for it
+	Rate func(name string)
+	// KV prints msg followed by fields from alternating keys and values, or
+	// only the fields when msg is empty, for event style entries
+	KV func(msg string, kvs ...interface{})
+	// Table prints a table of rows under headers as one entry, aligned in
+	// columns on a terminal, or with SetColor on, and as CSV lines otherwise
+	Table        func(headers []string, rows [][]string)
 	LevelPrinter struct {
 		Ln
 		F
 		S
 		C
+		CF
+		CS
 		Chk
+		ChkE
 		Err
+		Errw
+		Stack
+		S1
+		Retry
+		Raw1
+		ErrCode
+		Rate
+		KV
+		Table
+		p *printer
 	}
 	LevelSpec struct {
 		ID        int
@@ -91,18 +144,41 @@ type (
 
 	// Entry is a log entry to be printed as json to the log file
 	Entry struct {
-		Time         time.Time
-		Level        string
+		Time  time.Time
+		Level string
+		// LevelID is the numeric level that Level is the name of
+		LevelID      int
 		Package      string
 		CodeLocation string
-		Text         string
+		// Function is the full name of the function that logged the entry,
+		// when it is known
+		Function string
+		Text     string
+		// Fields are key/value pairs rendered after the text
+		Fields []Field
+		// Detail is printed on the lines beneath the entry, such as a stack
+		Detail string
+		// Host is the hostname set with SetHostname or SetHostnameValue
+		Host string
+		// Color is whether the entry is for a terminal, where encoders for
+		// people may color it
+		Color bool
+		// cfg is the config the entry was made with, which encoders render it
+		// with, nil for entries made elsewhere
+		cfg *config
+	}
+	// Field is a key/value pair attached to an Entry
+	Field struct {
+		Key   string
+		Value interface{}
 	}
+	// Encoder renders an Entry as the bytes written for it in a single Write
+	Encoder func(e *Entry) []byte
 )
 
 var (
 	// sep is just a convenient shortcut for this very longwinded expression
-	sep          = string(os.PathSeparator)
-	currentLevel = atomic.NewInt32(Info)
+	sep = string(os.PathSeparator)
 	// writer can be swapped out for any io.*writer* that you want to use instead of
 	// stdout.
 	writer io.Writer = os.Stderr
@@ -111,13 +187,36 @@ var (
 		{Off, "   ", color.Bit24(0, 0, 0, false).Sprint},
 		{Fatal, "FTL", color.Bit24(128, 0, 0, false).Sprint},
 		{Error, "ERR", color.Bit24(255, 0, 0, false).Sprint},
-		{Warn, "WRN", color.Bit24(0, 255, 0, false).Sprint},
-		{Info, "INF", color.Bit24(255, 255, 0, false).Sprint},
+		{Warn, "WRN", color.Bit24(255, 255, 0, false).Sprint},
+		{Info, "INF", color.Bit24(0, 255, 0, false).Sprint},
 		{Debug, "DBG", color.Bit24(0, 125, 255, false).Sprint},
 		{Trace, "TRC", color.Bit24(125, 0, 255, false).Sprint},
 	}
+	// LevelNames are the full lower case names of the levels, as used in
+	// structured output
+	LevelNames = []string{"off", "fatal", "error", "warn", "info", "debug",
+		"trace"}
 )
 
+// Flusher is implemented by writers that hold on to lines before writing them
+// out, such as asynchronous or buffered writers.
+type Flusher interface {
+	Flush() error
+}
+
+// levelWriter is implemented by writers that write each line at a priority of
+// their own, such as the one from NewSyslogWriter, which are given the level
+// of each line along with it.
+type levelWriter interface {
+	WriteLevel(level int, b []byte) (n int, err error)
+}
+
+// entryWriter is implemented by writers that take entries as they are rather
+// than encoded, such as the one from NewOtelWriter.
+type entryWriter interface {
+	writeEntry(e *Entry) error
+}
+
 // Log is a set of log printers for the various Level items.
 type Log struct {
 	F, E, W, I, D, T LevelPrinter
@@ -127,88 +226,761 @@ type Check struct {
 	F, E, W, I, D, T Chk
 }
 
+// JoinStrings renders each of a as fmt.Sprint does, with spaces between them.
 func JoinStrings(a ...any) (s string) {
+	return JoinStringsSep(" ", a...)
+}
+
+// JoinStringsSep is JoinStrings with sep between the values in place of a
+// space, such as a comma or a tab for lines that are split up again later.
+func JoinStringsSep(sep string, a ...any) (s string) {
 	for i := range a {
 		s += fmt.Sprint(a[i])
 		if i < len(a)-1 {
-			s += " "
+			s += sep
 		}
 	}
 	return
 }
 
+// SetMaxMessageLen cuts the message of each line to at most n bytes, not
+// counting the level, location or fields, ending it instead with
+// "…(truncated, total N bytes)" for its N bytes in full, so that a huge value
+// logged by mistake cannot fill a disk. Messages are only cut between runes.
+// Zero or less, the default, leaves them whole.
+func SetMaxMessageLen(n int) {
+	update(func(c *config) { c.maxMessageLen = max(0, n) })
+}
+
+// truncate cuts text to the length set with SetMaxMessageLen.
+func (c *config) truncate(text string) string {
+	n := c.maxMessageLen
+	if n == 0 || len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s…(truncated, total %d bytes)", text[:n], len(text))
+}
+
+// SetSpewConfig sets the spew settings that S dumps its values with, such as
+// one with MaxDepth set and DisablePointerAddresses on, so the output is the
+// same from run to run. The settings are copied, so later changes to cs have
+// no effect until it is set again. Passing nil restores spew.Config, the
+// default.
+func SetSpewConfig(cs *spew.ConfigState) {
+	if cs != nil {
+		cp := *cs
+		cs = &cp
+	}
+	update(func(c *config) { c.spewConfig = cs })
+}
+
+// sdump is spew.Sdump with the settings from SetSpewConfig.
+func (c *config) sdump(a ...interface{}) string {
+	if c.spewConfig != nil {
+		return c.spewConfig.Sdump(a...)
+	}
+	return spew.Sdump(a...)
+}
+
+// spewOneLine renders values compactly for S1, with stable output across runs.
+var spewOneLine = &spew.ConfigState{
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+	SortKeys:                true,
+}
+
+// spewLine renders each of a as a single line spew, separated by spaces, with
+// newlines inside values escaped.
+func spewLine(a ...interface{}) string {
+	s := make([]string, len(a))
+	for i := range a {
+		s[i] = strings.ReplaceAll(spewOneLine.Sprintf("%#v", a[i]), "\n", `\n`)
+	}
+	return strings.Join(s, " ")
+}
+
+// printer is what a LevelPrinter is built from, kept so that derived printers
+// can be made from it.
+type printer struct {
+	level  int32
+	writer io.Writer
+	// encoder renders each entry for writer, TextEncoder when nil.
+	encoder Encoder
+	// skip is the number of extra stack frames between the caller and the
+	// printer, for logs that are wrapped in helpers.
+	skip int
+	// ctx is consulted on every emission for request scoped details such as
+	// the time remaining before its deadline.
+	ctx context.Context
+	// dropped printers write nothing, such as those sampled out by SampleKey
+	// and the Debug and Trace printers of the lol_release tag.
+	dropped bool
+	// name is the subsystem of printers from NewNamed.
+	name string
+	// ofLog is whether the printer is one of a Log, rather than from
+	// GetPrinter.
+	ofLog bool
+	// tty is whether writer is a terminal, found once when the printer is
+	// given its writer and copied to the printers made from it.
+	tty bool
+	// guard counts the lines of the Log being written, for finding those
+	// logged by its writer while it writes another.
+	guard *writeGuard
+	// sample is n for printers from Sample, which write one line in n from
+	// each call site.
+	sample int
+	// indent is how many groups from Group the lines of the printer are in.
+	indent int
+	// with are the fields added by With, which are never changed in place
+	// as printers made from this one share them.
+	with []Field
+}
+
 func GetPrinter(l int32, writer io.Writer) LevelPrinter {
+	p := &printer{level: l, writer: writer, guard: &writeGuard{}}
+	p.detectTerminal()
+	return p.levelPrinter()
+}
+
+// Ctx returns a printer that appends details from ctx to every line: the key
+// from the correlator set with SetCorrelator, or else the ID from WithContext,
+// as correlation_id, the ID from WithTraceID, as trace_id, and the time
+// remaining until the deadline of ctx, as deadline_in. Each is left out when
+// there is nothing for it. Debug and Trace lines are dropped when the trace
+// sampler set with SetTraceSampler finds that the span in ctx is not sampled.
+func (lp LevelPrinter) Ctx(ctx context.Context) LevelPrinter {
+	if lp.p == nil {
+		return lp
+	}
+	p := *lp.p
+	p.ctx = ctx
+	return p.levelPrinter()
+}
+
+// With returns a printer like lp that adds fields from the alternating keys
+// and values of kvs, as for KV, to every line after the message, such as a
+// request ID for all the lines about one request. Fields from With on a
+// printer from With come after those of the printer it was called on.
+func (lp LevelPrinter) With(kvs ...interface{}) LevelPrinter {
+	if lp.p == nil || len(kvs) == 0 {
+		return lp
+	}
+	p := *lp.p
+	p.with = append(p.with[:len(p.with):len(p.with)], KVFields(kvs...)...)
+	return p.levelPrinter()
+}
+
+// Group writes title and returns a printer like lp whose lines are indented
+// two spaces further beneath it, so that the steps of an operation read as a
+// tree, along with a function to call once it is over that writes title
+// followed by done at the indent of lp. Groups from the printer returned nest
+// further. The indent belongs to the printers made from the one returned, so
+// operations on other goroutines are not indented by it, and it is only added
+// to text lines, not to structured ones.
+func (lp LevelPrinter) Group(title string) (group LevelPrinter, done func()) {
+	if lp.p == nil {
+		return lp, func() {}
+	}
+	p := lp.p
+	if c := p.enabled(); c != nil {
+		p.emit(c, title, "")
+	}
+	child := *p
+	child.indent++
+	return child.levelPrinter(), func() {
+		if c := p.enabled(); c != nil {
+			p.emit(c, title+" done", "")
+		}
+	}
+}
+
+// Skip returns a printer like lp that skips n more frames for the code
+// location of its lines, for helpers that wrap a printer, so that a line from
+// log.E.Skip(1).Ln in a helper has the location of the code calling the
+// helper. n is added to the skip of lp, such as one given to NewUnixgram, so
+// it counts only the frames between the caller of lp and the code it wraps.
+func (lp LevelPrinter) Skip(n int) LevelPrinter {
+	if lp.p == nil || n == 0 {
+		return lp
+	}
+	p := *lp.p
+	p.skip = max(0, p.skip+n)
+	return p.levelPrinter()
+}
+
+// As returns a printer like lp at another level, clamped as for Log.At, with
+// the same writer, context and caller skip, for the odd line that needs a
+// different severity from the printer at hand.
+func (lp LevelPrinter) As(level int) LevelPrinter {
+	if lp.p == nil {
+		return lp
+	}
+	p := *lp.p
+	p.level = clampLevel(level)
+	return p.levelPrinter()
+}
+
+// SampleKey returns a printer that keeps the lines for a fraction rate of all
+// keys, such as user IDs, and drops them for the rest. The decision is made by
+// hashing key, so it is the same every time for the same key and an entity's
+// lines are either all kept or all dropped.
+func (lp LevelPrinter) SampleKey(key string, rate float64) LevelPrinter {
+	if lp.p == nil || keepSample(key, rate) {
+		return lp
+	}
+	p := *lp.p
+	p.dropped = true
+	return p.levelPrinter()
+}
+
+// Sample returns a printer like lp that writes only the first line of every n
+// from each call site, dropping the rest, such as for Trace lines in a loop
+// over packets that would be too many to keep all of. Each statement that
+// logs through a printer from Sample is counted on its own, however many
+// printers it is called through. An n of 1 or less keeps every line.
+func (lp LevelPrinter) Sample(n int) LevelPrinter {
+	if lp.p == nil || n <= 1 {
+		return lp
+	}
+	p := *lp.p
+	p.sample = n
+	return p.levelPrinter()
+}
+
+// sampleCounts holds the count of lines from each call site of printers from
+// Sample, by program counter.
+var sampleCounts sync.Map
+
+// sampleKept counts a line from the call site at pc that is sampled one in n,
+// reporting whether it is kept.
+func sampleKept(pc uintptr, n int) bool {
+	v, ok := sampleCounts.Load(pc)
+	if !ok {
+		v, _ = sampleCounts.LoadOrStore(pc, atomic.NewUint64(0))
+	}
+	return (v.(*atomic.Uint64).Inc()-1)%uint64(n) == 0
+}
+
+// keepSample maps the FNV-1a hash of key onto [0,1) and keeps it when that is
+// below rate. The hash is put through the splitmix64 finalizer first, as on
+// its own FNV spreads keys that differ only at the end poorly in its top bits.
+func keepSample(key string, rate float64) bool {
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x)/(1<<64) < rate
+}
+
+// fields gathers the fields added after the message text.
+func (p *printer) fields(c *config) (f []Field) {
+	f = append(f, p.with...)
+	if p.ctx == nil {
+		return
+	}
+	if id := c.correlationID(p.ctx); id != "" {
+		f = append(f, Field{"correlation_id", id})
+	}
+	if id := TraceID(p.ctx); id != "" {
+		f = append(f, Field{"trace_id", id})
+	}
+	if d, ok := p.ctx.Deadline(); ok {
+		f = append(f,
+			Field{"deadline_in", time.Until(d).Round(time.Millisecond)})
+	}
+	return
+}
+
+// emit writes an entry for text with the settings in c, with detail on the
+// lines beneath it when detail is not empty, and extra after the fields of the
+// printer.
+func (p *printer) emit(c *config, text, detail string, extra ...Field) {
+	loc, function := caller(c, 2+p.skip)
+	if detail == "" && c.stackAt(p.level) {
+		detail = stack(2 + p.skip)
+	}
+	p.emitEntry(c, loc, function, text, detail, extra)
+}
+
+// emitChk is emit for the error e checked by Chk, with the stack attached
+// that chkStack calls for.
+func (p *printer) emitChk(c *config, e error, extra ...Field) {
+	loc, function := caller(c, 2+p.skip)
+	p.emitEntry(c, loc, function, e.Error(), c.chkStack(p.level, e, 2+p.skip),
+		extra)
+}
+
+// emitAt is emit for an entry with the code location loc.
+func (p *printer) emitAt(c *config, loc, text, detail string,
+	extra ...Field) {
+	p.emitEntry(c, loc, "", text, detail, extra)
+}
+
+// emitEntry writes the entry made from the arguments of emit, with the code
+// location loc in function.
+func (p *printer) emitEntry(c *config, loc, function, text, detail string,
+	extra []Field) {
+	if !c.ringsOnly {
+		levelCounts[p.level].Inc()
+	}
+	e := c.entry(p.level, loc, c.truncate(p.indented(c, c.redactText(text))))
+	e.Function = function
+	e.Fields = append(p.fields(c), extra...)
+	e.Detail = detail
+	e.Color = c.colorFor(p)
+	if c.ringsOnly {
+		p.writeRings(c, e)
+		return
+	}
+	c.runHooks(e)
+	if p.exits() {
+		// the line must be out before exiting, not held for a report, after
+		// the count of any line repeated before it
+		if c.dedup {
+			Flush()
+		}
+		p.write(c, e)
+		c.exit(1)
+		return
+	}
+	if c.rateLimited(e) || aggregated(c, p, e) || repeated(c, p, e) {
+		return
+	}
+	p.write(c, e)
+}
+
+// exits is whether the printer is the Fatal printer of a Log, which ends the
+// program once its line is written.
+func (p *printer) exits() bool {
+	return p.ofLog && p.level == Fatal
+}
+
+// indented is text with the indent of the groups the printer is in, for text
+// lines.
+func (p *printer) indented(c *config, text string) string {
+	if p.indent == 0 || p.structured(c) {
+		return text
+	}
+	return strings.Repeat("  ", p.indent) + text
+}
+
+// write encodes e to the writer of the printer, or hands it over as it is to
+// one that takes entries, flushing it afterwards if the level calls for it
+// with the settings in c. A line logged by a writer from within its own write
+// goes to os.Stderr instead.
+func (p *printer) write(c *config, e *Entry) {
+	if c.capture != nil {
+		c.capture.add(p.encoderFor(c)(e))
+		return
+	}
+	g := p.writeGuard()
+	if g.writing.Load() > 0 && inWrite() {
+		p.writeReentrant(c, e)
+		return
+	}
+	g.writing.Inc()
+	defer g.writing.Dec()
+	var err error
+	switch w := p.writer.(type) {
+	case entryWriter:
+		err = w.writeEntry(e)
+	case levelWriter:
+		_, err = w.WriteLevel(int(p.level), p.encoderFor(c)(e))
+	default:
+		_, err = w.Write(p.encoderFor(c)(e))
+	}
+	if err != nil {
+		c.writeError(err)
+	}
+	if p.level == Fatal {
+		c.drain(p.writer)
+	} else if c.flushLevel != Off && p.level <= c.flushLevel {
+		if f, ok := p.writer.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				c.writeError(err)
+			}
+		}
+	}
+}
+
+// encoderFor is the encoder of the printer, or for printers without one,
+// JSONEncoder in JSON mode and TextEncoder otherwise.
+func (p *printer) encoderFor(c *config) Encoder {
+	switch {
+	case p.encoder != nil:
+		return p.encoder
+	case c.jsonMode:
+		return JSONEncoder
+	}
+	return TextEncoder
+}
+
+// structured is whether the entries of the printer are encoded for machines
+// rather than people, so that an error is worth its own err field.
+func (p *printer) structured(c *config) bool {
+	return p.encoder != nil || c.jsonMode
+}
+
+// errField is the err field for an error logged by Chk and the like, added
+// only to structured entries as text entries already show the error.
+func (p *printer) errField(c *config, err error) []Field {
+	if !p.structured(c) {
+		return nil
+	}
+	return []Field{{"err", err}}
+}
+
+// TextEncoder renders an entry as the timestamp, level, host if set, message,
+// fields, in the order set with SetFieldOrder, and code location on one line,
+// followed by any detail on the lines beneath. For entries with Color set,
+// the level and code location are colored, and nothing else, so that
+// searching the text of lines still works.
+func TextEncoder(e *Entry) []byte {
+	var b strings.Builder
+	c := e.config()
+	if ts := c.timestamp(e.Time); ts != "" {
+		b.WriteString(ts)
+		b.WriteByte(' ')
+	}
+	if e.Color {
+		b.WriteString(LevelSpecs[e.LevelID].Colorizer(LevelSpecs[e.LevelID].Name))
+	} else {
+		b.WriteString(LevelSpecs[e.LevelID].Name)
+	}
+	b.WriteByte(' ')
+	if e.Host != "" {
+		b.WriteString("[" + e.Host + "] ")
+	}
+	b.WriteString(c.scrubText(e.Text))
+	// entries of only fields start with the first of them
+	sep := " "
+	if e.Text == "" {
+		sep = ""
+	}
+	fields, dropped := c.encodeFields(c.orderFields(e.Fields))
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s%s=%v", sep, f.Key, f.Value)
+		sep = " "
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&b, "%s...(+%d fields)", sep, dropped)
+	}
+	b.WriteByte(' ')
+	if e.Color {
+		b.WriteString(color.Bit24(0, 128, 255, false).Sprint(e.CodeLocation))
+	} else {
+		b.WriteString(e.CodeLocation)
+	}
+	b.WriteByte('\n')
+	if e.Detail != "" {
+		b.WriteString(e.Detail)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// enabled returns the config to write a line with when the printer writes
+// anything at all, which needs its level to be within the current log level,
+// or the one set for the file of the caller, or a RingWriter to write to, or
+// nil when it does not. It is checked before any formatting is done, without
+// allocating for lines within the level, and the config it returns is used for
+// the rest of the line.
+func (p *printer) enabled() *config {
+	c := loadConfig()
+	var why, level string
+	var l int32
+	if !p.dropped {
+		l = c.levelFor(p.name, 2+p.skip)
+	}
+	above := p.level > l
+	if p.dropped {
+		why = "dropped by SampleKey or the lol_release tag"
+	} else if above && !holdsRing(p.writer) {
+		why = "above the log level "
+		level = LevelNames[max(Off, min(Trace, l))]
+	} else if p.ctx != nil && p.level >= Debug && !c.traceSampled(p.ctx) {
+		why = "for a span that is not sampled"
+	} else if c.locFilter != nil && !c.locFilter.allows(c, 2+p.skip) {
+		why = "filtered out by SetLocFilter"
+	} else if p.sample > 1 && !p.sampleKept() {
+		why = "sampled out by Sample"
+	} else if above {
+		return c.forRings()
+	} else {
+		return c
+	}
+	if c.debugInternal {
+		c.debugf("%s line at %s suppressed, %s%s", LevelNames[p.level],
+			location(c, 2+p.skip), why, level)
+	}
+	return nil
+}
+
+// enabledAt is enabled for a line with the code location of the program
+// counter pc, for lines that are logged away from the code they belong to,
+// such as those of WatchDiff and slog records.
+func (p *printer) enabledAt(pc uintptr) *config {
+	c := loadConfig()
+	var why, level string
+	var l int32
+	if !p.dropped {
+		l = c.levelAt(c.subsystemLevel(p.name), pc)
+	}
+	above := p.level > l
+	if p.dropped {
+		why = "dropped by SampleKey or the lol_release tag"
+	} else if above && !holdsRing(p.writer) {
+		why = "above the log level "
+		level = LevelNames[max(Off, min(Trace, l))]
+	} else if p.ctx != nil && p.level >= Debug && !c.traceSampled(p.ctx) {
+		why = "for a span that is not sampled"
+	} else if c.locFilter != nil && !c.locFilter.allowsAt(c, pc) {
+		why = "filtered out by SetLocFilter"
+	} else if above {
+		return c.forRings()
+	} else {
+		return c
+	}
+	if c.debugInternal {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		c.debugf("%s line at %s suppressed, %s%s", LevelNames[p.level],
+			locationAt(c, frame.PC, frame.File, frame.Line), why, level)
+	}
+	return nil
+}
+
+// sampleKept counts a line from a printer from Sample against its call site,
+// which is three frames up from here, reporting whether it is kept.
+func (p *printer) sampleKept() bool {
+	var pc [1]uintptr
+	if runtime.Callers(4+p.skip, pc[:]) == 0 {
+		return true
+	}
+	return sampleKept(pc[0], p.sample)
+}
+
+// Enabled is whether lp writes anything at all, other than lines above the log
+// level for a RingWriter, for guarding lines whose arguments are costly to
+// work out, as the arguments of Ln and F are still evaluated, and put in a
+// slice, for a printer that writes nothing:
+//
+//	if log.T.Enabled() {
+//		log.T.F("state %s", dump(state))
+//	}
+func (lp LevelPrinter) Enabled() bool {
+	if lp.p == nil {
+		return false
+	}
+	c := lp.p.enabled()
+	return c != nil && !c.ringsOnly
+}
+
+// detectTerminal sets tty for the writer of p, and has a console render the
+// colors of lines on Windows, for printers given a writer. The printers made
+// from them copy tty rather than looking again.
+func (p *printer) detectTerminal() {
+	if p.tty = isTerminal(p.writer); p.tty {
+		enableVT(p.writer)
+	}
+}
+
+func (p *printer) levelPrinter() LevelPrinter {
 	return LevelPrinter{
 		Ln: func(a ...interface{}) {
-			fmt.Fprintf(writer,
-				"%s %s %s %s\n",
-				UnixNanoAsFloat(),
-				LevelSpecs[l].Colorizer(LevelSpecs[l].Name),
-				JoinStrings(a...),
-				GetLoc(2),
-			)
+			if c := p.enabled(); c != nil {
+				p.emit(c, JoinStrings(a...), "")
+			}
 		},
 		F: func(format string, a ...interface{}) {
-			fmt.Fprintf(writer,
-				"%s %s %s %s\n",
-				UnixNanoAsFloat(),
-				LevelSpecs[l].Colorizer(LevelSpecs[l].Name),
-				fmt.Sprintf(format, a...),
-				GetLoc(2),
-			)
+			if c := p.enabled(); c != nil {
+				p.emit(c, fmt.Sprintf(format, a...), "")
+			}
 		},
 		S: func(a ...interface{}) {
-			fmt.Fprintf(writer,
-				"%s %s %s %s\n",
-				UnixNanoAsFloat(),
-				LevelSpecs[l].Colorizer(LevelSpecs[l].Name),
-				spew.Sdump(a...),
-				GetLoc(2),
-			)
+			if c := p.enabled(); c != nil {
+				p.emit(c, c.sdump(c.redactArgs(a)...), "")
+			}
 		},
 		C: func(closure func() string) {
-			fmt.Fprintf(writer,
-				"%s %s %s %s\n",
-				UnixNanoAsFloat(),
-				LevelSpecs[l].Colorizer(LevelSpecs[l].Name),
-				closure(),
-				GetLoc(2),
-			)
+			if c := p.enabled(); c != nil {
+				p.emit(c, closure(), "")
+			}
+		},
+		CF: func(closure func() (string, []interface{})) {
+			if c := p.enabled(); c != nil {
+				format, a := closure()
+				p.emit(c, fmt.Sprintf(format, a...), "")
+			}
+		},
+		CS: func(closure func() interface{}) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, c.sdump(c.redactArgs([]interface{}{closure()})...), "")
+			}
 		},
 		Chk: func(e error) bool {
 			if e != nil {
-				fmt.Fprintf(writer,
-					"%s %s %s %s\n",
-					UnixNanoAsFloat(),
-					LevelSpecs[l].Colorizer(LevelSpecs[l].Name),
-					e.Error(),
-					GetLoc(2),
-				)
+				if c := p.enabled(); c != nil && !c.chkIgnored(e) {
+					p.emitChk(c, e, p.errField(c, e)...)
+				}
 				return true
 			}
 			return false
 		},
+		ChkE: func(e error) error {
+			if e == nil {
+				return nil
+			}
+			c := loadConfig()
+			err := &locatedError{location(c, 1+p.skip), e}
+			if c = p.enabled(); c != nil && !c.chkIgnored(e) {
+				p.emitChk(c, e, p.errField(c, err)...)
+			}
+			return err
+		},
 		Err: func(format string, a ...interface{}) error {
-			fmt.Fprintf(writer,
-				"%s %s %s %s\n",
-				UnixNanoAsFloat(),
-				LevelSpecs[l].Colorizer(LevelSpecs[l].Name, " "),
-				fmt.Sprintf(format, a...),
-				GetLoc(2),
-			)
-			return fmt.Errorf(format, a...)
+			err := p.errorf(format, a...)
+			if c := p.enabled(); c != nil {
+				p.emit(c, fmt.Sprintf(format, a...), "", p.errField(c, err)...)
+			}
+			return err
+		},
+		Errw: func(wrapped error, format string, a ...interface{}) error {
+			// with nothing to wrap it is the same as Err
+			if wrapped != nil {
+				format, a = format+": %w", append(a[:len(a):len(a)], wrapped)
+			}
+			err := p.errorf(format, a...)
+			if c := p.enabled(); c != nil {
+				p.emit(c, fmt.Errorf(format, a...).Error(), "",
+					p.errField(c, err)...)
+			}
+			return err
+		},
+		Stack: func(a ...interface{}) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, JoinStrings(a...), stack(1+p.skip))
+			}
 		},
+		S1: func(a ...interface{}) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, spewLine(c.redactArgs(a)...), "")
+			}
+		},
+		Retry: func(attempt int, wait time.Duration, err error) {
+			if c := p.enabled(); err != nil && c != nil {
+				p.emit(c, fmt.Sprintf("attempt %d failed: %v; retrying in %v",
+					attempt, err, wait), "")
+			}
+		},
+		Raw1: func(s string) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, s, "")
+			}
+		},
+		ErrCode: func(code int, format string, a ...interface{}) error {
+			err := &CodedError{code, p.errorf(format, a...)}
+			if c := p.enabled(); c != nil {
+				p.emit(c, codePrefix(code)+fmt.Errorf(format, a...).Error(), "",
+					p.errField(c, err)...)
+			}
+			return err
+		},
+		Rate: func(name string) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, rateText(name), "")
+			}
+		},
+		KV: func(msg string, kvs ...interface{}) {
+			if c := p.enabled(); c != nil {
+				p.emit(c, msg, "", KVFields(kvs...)...)
+			}
+		},
+		Table: func(headers []string, rows [][]string) {
+			if c := p.enabled(); c != nil {
+				text, detail := p.tableText(c, headers, rows)
+				p.emit(c, text, detail)
+			}
+		},
+		p: p,
+	}
+}
+
+// locatedError is the error returned by ChkE, which reads as the location it
+// was checked at followed by the error it wraps.
+type locatedError struct {
+	loc string
+	err error
+}
+
+// locSep is what separates a code location from the error text it leads, in the
+// errors of ChkE and of Err with SetErrIncludeLoc.
+const locSep = ": "
+
+func (l *locatedError) Error() string { return l.loc + locSep + l.err.Error() }
+func (l *locatedError) Unwrap() error { return l.err }
+
+// errorf is fmt.Errorf for the errors returned by Err and the like, as a
+// LogError with the location of their caller, which the message starts with
+// when SetErrIncludeLoc is on.
+func (p *printer) errorf(format string, a ...interface{}) error {
+	c := loadConfig()
+	err := fmt.Errorf(format, a...)
+	return &LogError{
+		Msg:    err.Error(),
+		Loc:    location(c, 2+p.skip),
+		Level:  LevelNames[p.level],
+		Fields: p.fields(c),
+		err:    err,
+		cfg:    c,
 	}
 }
 
+// New returns a Log writing to writer, and the Check of its Chk printers.
+// Each line is written with one Write and writer is not locked, so a writer
+// that is unsafe to share between goroutines should be wrapped in SyncWriter.
 func New(writer io.Writer) (l *Log, c *Check) {
+	return newLog(printer{writer: writer})
+}
+
+// NewWithEncoder is New for a Log that renders its entries with enc, such as
+// JSONEncoder, in place of TextEncoder.
+func NewWithEncoder(writer io.Writer, enc Encoder) (l *Log, c *Check) {
+	return newLog(printer{writer: writer, encoder: enc})
+}
+
+// newLog is New for printers at each level made from the template p.
+func newLog(p printer) (l *Log, c *Check) {
+	p.ofLog = true
+	p.guard = &writeGuard{}
+	p.detectTerminal()
+	get := func(level int32) LevelPrinter {
+		lp := p
+		lp.level = level
+		return lp.levelPrinter()
+	}
 	l = &Log{
-		F: GetPrinter(Fatal, writer),
-		E: GetPrinter(Error, writer),
-		W: GetPrinter(Warn, writer),
-		I: GetPrinter(Info, writer),
-		D: GetPrinter(Debug, writer),
-		T: GetPrinter(Trace, writer),
+		F: get(Fatal),
+		E: get(Error),
+		W: get(Warn),
+		I: get(Info),
+		D: get(Debug),
+		T: get(Trace),
+	}
+	if releaseBuild {
+		d, t := p, p
+		d.level, t.level = Debug, Trace
+		l.D, l.T = nullLevelPrinter(d), nullLevelPrinter(t)
 	}
 	c = &Check{
 		F: l.F.Chk,
@@ -221,20 +993,315 @@ func New(writer io.Writer) (l *Log, c *Check) {
 	return
 }
 
+// At returns the printer of l for level, clamped to the range from Fatal to
+// Trace.
+func (l *Log) At(level int) LevelPrinter {
+	switch clampLevel(level) {
+	case Fatal:
+		return l.F
+	case Error:
+		return l.E
+	case Warn:
+		return l.W
+	case Info:
+		return l.I
+	case Debug:
+		return l.D
+	}
+	return l.T
+}
+
+// clampLevel limits level to the printing levels, from Fatal to Trace.
+func clampLevel(level int) int32 {
+	switch {
+	case level < Fatal:
+		return Fatal
+	case level > Trace:
+		return Trace
+	}
+	return int32(level)
+}
+
 // SetLogLevel sets the log level via a string, which can be truncated down to
 // one character, similar to nmcli's argument processor, as the first letter is
 // unique. This could be used with a linter to make larger command sets.
 func SetLogLevel(l int) {
-	currentLevel.Store(int32(l))
+	update(func(c *config) { c.level = int32(l) })
 }
 
 func GetLogLevel() (l int) {
-	return int(currentLevel.Load())
+	return int(loadConfig().level)
+}
+
+// SetColor turns coloring of the level and code location of text lines on or
+// off for every writer, in place of coloring only those that are terminals,
+// for when that is not detected right, such as a terminal behind a buffered
+// writer. On Windows the consoles of os.Stdout and os.Stderr are set to render
+// the colors when it is turned on, as they are for printers writing to a
+// console.
+func SetColor(enabled bool) {
+	mode := colorOff
+	if enabled {
+		mode = colorOn
+		enableVT(os.Stdout)
+		enableVT(os.Stderr)
+	}
+	update(func(c *config) { c.color = mode })
+}
+
+// colorFor is whether the entries of p are colored.
+func (c *config) colorFor(p *printer) bool {
+	switch c.color {
+	case colorOn:
+		return true
+	case colorOff:
+		return false
+	}
+	return p.tty
+}
+
+// SetJSONMode makes printers that were not given an encoder, such as those
+// from New, write each entry as a JSON object on a line with JSONEncoder in
+// place of TextEncoder, for log ingestion pipelines. Entries from Chk, Err and
+// ErrCode get the error as an err field as well. It can be switched at any
+// time, and each line is written wholly in one mode or the other.
+func SetJSONMode(enabled bool) {
+	update(func(c *config) { c.jsonMode = enabled })
+}
+
+// SetExitFunc sets the function that the Fatal printer of a Log calls with 1
+// after writing its line and draining the writer, which is os.Exit by
+// default. Tests can record the code rather than end, and programs can shut
+// down gracefully. Printers at other levels, and a Fatal one from GetPrinter,
+// never exit, nor does the Fatal printer when the log level is Off and it
+// writes nothing. Passing nil restores os.Exit.
+func SetExitFunc(fn func(code int)) {
+	update(func(c *config) { c.exitFunc = fn })
+}
+
+// exit ends the program with code through the exit function.
+func (c *config) exit(code int) {
+	if c.exitFunc != nil {
+		c.exitFunc(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// SetFatalDrainTimeout sets how long a Fatal line waits for a writer that
+// implements Flusher to drain, so the line explaining why the program is dying
+// is not lost in a buffer. Zero or less skips the wait entirely.
+func SetFatalDrainTimeout(d time.Duration) {
+	update(func(c *config) { c.fatalDrainTimeout = d })
+}
+
+// SetFlushLevel makes lines at level or a more severe one flush a writer that
+// implements Flusher, such as a bufio.Writer, straight after they are written,
+// while less severe lines stay buffered. Off, the default, leaves flushing to
+// the writer, except that Fatal lines always drain it.
+func SetFlushLevel(level int) {
+	update(func(c *config) { c.flushLevel = int32(level) })
+}
+
+// SetErrIncludeLoc makes the errors returned by Err start with the file:line of
+// the call and a colon, as those of ChkE do, so the error still says where it
+// came from once it has propagated far from the log line. It is off by
+// default.
+func SetErrIncludeLoc(include bool) {
+	update(func(c *config) { c.errIncludeLoc = include })
+}
+
+// SetChkIgnore sets errors, such as sql.ErrNoRows, that are expected and so
+// are not logged by Chk, including when they are wrapped. Chk still returns
+// true for them, so the code handling the error runs as before. Calling it
+// with no errors logs them all again.
+func SetChkIgnore(errs ...error) {
+	update(func(c *config) { c.chkIgnore = errs })
+}
+
+// chkIgnored is whether err is one that Chk does not log.
+func (c *config) chkIgnored(err error) bool {
+	for _, i := range c.chkIgnore {
+		if errors.Is(err, i) {
+			c.debugf("%q not logged by Chk, it is set to be ignored", err)
+			return true
+		}
+	}
+	return false
+}
+
+// SetHostname turns on or off tagging every entry with the hostname, which is
+// looked up once here rather than for each line.
+func SetHostname(enabled bool) {
+	var h string
+	if enabled {
+		var err error
+		if h, err = os.Hostname(); err != nil {
+			h = "unknown"
+		}
+	}
+	SetHostnameValue(h)
+}
+
+// SetHostnameValue tags every entry with host in place of the kernel
+// hostname, for containers where that is not meaningful. An empty host turns
+// tagging off.
+func SetHostnameValue(host string) {
+	update(func(c *config) { c.hostname = host })
+}
+
+// SetCorrelator sets a function that computes a correlation key for the
+// context given to LevelPrinter.Ctx, such as from request attributes or a
+// tracing system, which is added to each line as correlation_id. An empty key
+// adds nothing. Passing nil stops adding keys.
+func SetCorrelator(fn func(ctx context.Context) string) {
+	update(func(c *config) { c.correlator = fn })
+}
+
+// SetTraceSampler sets a function that reports whether ctx carries a span of
+// a distributed trace and whether that span is sampled, so that printers from
+// LevelPrinter.Ctx keep log volume in line with the trace: Debug and Trace
+// lines are dropped for spans that are not sampled, while contexts without a
+// span log as usual. With OpenTelemetry, for instance:
+//
+//	lol.SetTraceSampler(func(ctx context.Context) (sampled, present bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.IsSampled(), sc.IsValid()
+//	})
+//
+// Passing nil stops consulting the trace.
+func SetTraceSampler(fn func(ctx context.Context) (sampled, present bool)) {
+	update(func(c *config) { c.traceSampler = fn })
+}
+
+// traceSampled is whether lines for ctx are kept by the trace sampler.
+func (c *config) traceSampled(ctx context.Context) bool {
+	if c.traceSampler == nil {
+		return true
+	}
+	sampled, present := c.traceSampler(ctx)
+	return sampled || !present
+}
+
+// SetWriteErrorHandler sets a function that is called with the error when a
+// writer fails to take a log line. By default such errors are dropped, as there
+// is nowhere sensible to log them. Passing nil restores the default.
+func SetWriteErrorHandler(fn func(error)) {
+	update(func(c *config) { c.writeErrorHandler = fn })
+}
+
+func (c *config) writeError(err error) {
+	c.debugf("writer failed: %v", err)
+	if c.writeErrorHandler != nil {
+		c.writeErrorHandler(err)
+	}
+}
+
+// drain flushes writer if it is a Flusher, giving up after the fatal drain
+// timeout with a notice written directly to stderr.
+func (c *config) drain(writer io.Writer) {
+	f, ok := writer.(Flusher)
+	if !ok {
+		return
+	}
+	d := c.fatalDrainTimeout
+	if d <= 0 {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = f.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		fmt.Fprintf(os.Stderr,
+			"lol: log writer did not drain within %v, lines may be lost\n", d)
+	}
+}
+
+// SetTimeFormatter sets a function that renders the timestamp at the start of
+// each line, such as Unix milliseconds as an integer. It is called once per
+// line with the time of the line. Passing nil restores the default seconds
+// with nanosecond fraction.
+func SetTimeFormatter(fn func(time.Time) string) {
+	update(func(c *config) { c.timeFormatter, c.noTimestamp = fn, false })
+}
+
+// SetTimeFormat renders the timestamp at the start of each line with layout,
+// as for time.Format, such as 2006-01-02T15:04:05.000Z07:00. An empty layout
+// leaves the timestamp off lines altogether. Until either this or
+// SetTimeFormatter is called, lines start with Unix seconds with a nanosecond
+// fraction.
+func SetTimeFormat(layout string) {
+	var fn func(time.Time) string
+	if layout != "" {
+		fn = func(t time.Time) string { return t.Format(layout) }
+	}
+	update(func(c *config) { c.timeFormatter, c.noTimestamp = fn, layout == "" })
+}
+
+// startTime is when the package was initialized, which SetRelativeTime counts
+// from.
+var startTime = time.Now()
+
+// SetRelativeTime starts lines with the time since the program started, as
+// +0.342s, in place of the time of day, for reading off how long things took.
+// It replaces the format set with SetTimeFormat or SetTimeFormatter, as they
+// replace it, whichever is called last. Turning it off restores the default
+// Unix seconds.
+func SetRelativeTime(enabled bool) {
+	var fn func(time.Time) string
+	if enabled {
+		fn = func(t time.Time) string {
+			return fmt.Sprintf("+%.3fs", t.Sub(startTime).Seconds())
+		}
+	}
+	update(func(c *config) { c.timeFormatter, c.noTimestamp = fn, false })
+}
+
+// SetClock sets the function that gives the time of each entry, taken once
+// when it is logged, such as a fixed time for tests to get the same output
+// every run. Passing nil restores time.Now.
+func SetClock(now func() time.Time) {
+	update(func(c *config) { c.clock = now })
+}
+
+// now is the time from the clock set with SetClock.
+func (c *config) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// Timestamp renders t as it appears at the start of a line, which is nothing
+// when SetTimeFormat has turned timestamps off.
+func Timestamp(t time.Time) (s string) {
+	return loadConfig().timestamp(t)
+}
+
+// timestamp is Timestamp with the settings of c.
+func (c *config) timestamp(t time.Time) (s string) {
+	if c.noTimestamp {
+		return
+	}
+	if fn := c.timeFormatter; fn != nil {
+		s = fn(t)
+	} else {
+		s = unixNanoAsFloat(t)
+	}
+	return
 }
 
 // UnixNanoAsFloat e
 func UnixNanoAsFloat() (s string) {
-	timeText := fmt.Sprint(time.Now().UnixNano())
+	return color.Bit24(0, 128, 255, false).Sprint(unixNanoAsFloat(time.Now()))
+}
+
+func unixNanoAsFloat(t time.Time) (s string) {
+	timeText := fmt.Sprint(t.UnixNano())
 	lt := len(timeText)
 	lb := lt + 1
 	var timeBytes = make([]byte, lb)
@@ -243,13 +1310,44 @@ func UnixNanoAsFloat() (s string) {
 	lb -= 10
 	lt -= 9
 	copy(timeBytes[:lb], timeText[:lt])
-	return color.Bit24(0, 128, 255, false).Sprint(string(timeBytes))
+	return string(timeBytes)
 }
 
 func GetLoc(skip int) (output string) {
-	_, file, line, _ := runtime.Caller(skip)
-	output = color.Bit24(0, 128, 255, false).Sprint(
-		file, ":", line,
-	)
+	output = color.Bit24(0, 128, 255, false).Sprint(location(loadConfig(), skip))
+	return
+}
+
+// location is the uncolored file:line of the caller skip frames up, counted the
+// same way as runtime.Caller counts from the caller of location, as c has it
+// shown.
+func location(c *config, skip int) (loc string) {
+	loc, _ = caller(c, skip+1)
+	return
+}
+
+// caller is location along with the full name of the function at it.
+func caller(c *config, skip int) (loc, function string) {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		c.debugf("no caller %d frames up for a code location, "+
+			"the skip may be too large", skip+1)
+	}
+	loc = locationAt(c, pc, file, line)
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		function = fn.Name()
+	}
+	return
+}
+
+// locationAt is the code location of line in file, which the program counter
+// pc is in.
+func locationAt(c *config, pc uintptr, file string, line int) (loc string) {
+	loc = fmt.Sprint(locFile(c.locFormat, pc, file), ":", line)
+	if c.locModuleVersion {
+		if m := moduleOf(pc); m != "" {
+			loc = m + " " + loc
+		}
+	}
 	return
 }
@@ -1,15 +1,35 @@
 package lol_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/davecgh/go-spew/spew"
 	"github.com/mleku/lol"
 )
 
 var log, chk = lol.New(os.Stdout)
 
+// exitCodes records the exits of Fatal printers, which would otherwise end the
+// tests.
+var exitCodes []int
+
+func TestMain(m *testing.M) {
+	lol.SetExitFunc(func(code int) { exitCodes = append(exitCodes, code) })
+	os.Exit(m.Run())
+}
+
 func TestGetLogger(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		lol.SetLogLevel(lol.Trace)
@@ -32,3 +52,643 @@ func TestGetLogger(t *testing.T) {
 		log.I.S("`backtick wrapped string`", t)
 	}
 }
+
+type flushWriter struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (f *flushWriter) Flush() error {
+	f.flushed++
+	return nil
+}
+
+func TestFatalDrain(t *testing.T) {
+	w := &flushWriter{}
+	l, _ := lol.New(w)
+	l.E.Ln("not fatal")
+	if w.flushed != 0 {
+		t.Fatalf("error line flushed the writer")
+	}
+	l.F.Ln("fatal")
+	if w.flushed != 1 {
+		t.Fatalf("fatal line flushed the writer %d times, want 1", w.flushed)
+	}
+}
+
+func TestErrIncludeLoc(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	if e := l.E.Err("plain %d", 1); e.Error() != "plain 1" {
+		t.Fatalf("unexpected error text %q", e.Error())
+	}
+	lol.SetErrIncludeLoc(true)
+	defer lol.SetErrIncludeLoc(false)
+	e := l.E.Err("located %d", 2)
+	if !strings.Contains(e.Error(), "log_test.go:") ||
+		!strings.HasSuffix(e.Error(), ": located 2") {
+		t.Fatalf("error text %q does not carry its location", e.Error())
+	}
+}
+
+func TestCtxDeadline(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.Ctx(context.Background()).Ln("no deadline")
+	if strings.Contains(buf.String(), "deadline_in=") {
+		t.Fatalf("deadline added without one set: %q", buf.String())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	buf.Reset()
+	l.I.Ctx(ctx).Ln("with deadline")
+	if !strings.Contains(buf.String(), "with deadline deadline_in=") {
+		t.Fatalf("deadline missing: %q", buf.String())
+	}
+}
+
+func TestTimeFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetTimeFormatter(func(t time.Time) string {
+		return "ts=" + strconv.FormatInt(t.UnixMilli(), 10)
+	})
+	defer lol.SetTimeFormatter(nil)
+	l.I.Ln("stamped")
+	if !strings.Contains(buf.String(), "ts=") {
+		t.Fatalf("time formatter not used: %q", buf.String())
+	}
+}
+
+func TestS1(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	type pair struct {
+		Name string
+		N    *int
+	}
+	n := 3
+	l.I.S1(pair{"a\nb", &n}, map[string]int{"y": 2, "x": 1})
+	out := buf.String()
+	if strings.Count(out, "\n") != 1 {
+		t.Fatalf("S1 output spans lines: %q", out)
+	}
+	if !strings.Contains(out, `(lol_test.pair){Name:(string)a\nb N:(*int)3} (map[string]int)map[`) {
+		t.Fatalf("unexpected S1 output %q", out)
+	}
+}
+
+type requestKey struct{}
+
+func TestCorrelator(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetCorrelator(func(ctx context.Context) string {
+		id, _ := ctx.Value(requestKey{}).(string)
+		return id
+	})
+	defer lol.SetCorrelator(nil)
+	l.I.Ctx(context.Background()).Ln("anonymous")
+	if strings.Contains(buf.String(), "correlation_id") {
+		t.Fatalf("empty correlation key added: %q", buf.String())
+	}
+	ctx := context.WithValue(context.Background(), requestKey{}, "req-7")
+	l.I.Ctx(ctx).Ln("correlated")
+	if !strings.Contains(buf.String(), "correlated correlation_id=req-7") {
+		t.Fatalf("correlation key missing: %q", buf.String())
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.W.Retry(1, time.Second, nil)
+	if buf.Len() != 0 {
+		t.Fatalf("retry logged without an error: %q", buf.String())
+	}
+	l.W.Retry(2, 1500*time.Millisecond, errors.New("connection refused"))
+	if !strings.Contains(buf.String(),
+		"attempt 2 failed: connection refused; retrying in 1.5s") {
+		t.Fatalf("unexpected retry line %q", buf.String())
+	}
+}
+
+func TestHostname(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetHostnameValue("web-1")
+	l.I.Ln("tagged")
+	lol.SetHostname(false)
+	l.I.Ln("untagged")
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.Contains(lines[0], " [web-1] tagged ") {
+		t.Fatalf("host missing from %q", lines[0])
+	}
+	if strings.Contains(lines[1], "web-1") {
+		t.Fatalf("host still added after turning it off: %q", lines[1])
+	}
+}
+
+func TestFlushLevel(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	l, _ := lol.New(w)
+	lol.SetFlushLevel(lol.Error)
+	defer lol.SetFlushLevel(lol.Off)
+	l.I.Ln("buffered")
+	if buf.Len() != 0 {
+		t.Fatalf("info line flushed")
+	}
+	l.E.Ln("flushed")
+	if !strings.Contains(buf.String(), "buffered") ||
+		!strings.Contains(buf.String(), "flushed") {
+		t.Fatalf("error line did not flush: %q", buf.String())
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f := lol.Flags{"ready": true, "connected": false, "authed": true}
+	if got := lol.JoinStrings("status", f); got !=
+		"status [authed,!connected,ready]" {
+		t.Fatalf("unexpected flags rendering %q", got)
+	}
+	e := &lol.Entry{Fields: []lol.Field{{"flags", f}}}
+	if got := string(lol.JSONEncoder(e)); !strings.Contains(got,
+		`"fields":{"flags":{"authed":true,"connected":false,"ready":true}}`) {
+		t.Fatalf("flags not a JSON object in %s", got)
+	}
+}
+
+// skipInRelease skips a test that needs the Debug and Trace printers of a Log,
+// which print nothing with the lol_release tag.
+func skipInRelease(t *testing.T) {
+	t.Helper()
+	if releaseBuild {
+		t.Skip("needs the Debug and Trace printers of a Log")
+	}
+}
+
+func TestAs(t *testing.T) {
+	skipInRelease(t)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	l.D.Ctx(ctx).As(lol.Error).Ln("bumped")
+	out := buf.String()
+	if !strings.Contains(out, lol.LevelSpecs[lol.Error].Name) ||
+		!strings.Contains(out, "bumped deadline_in=") ||
+		!strings.Contains(out, "log_test.go:") {
+		t.Fatalf("As did not keep the printer setup: %q", out)
+	}
+}
+
+func TestSampleKey(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	var kept int
+	for i := 0; i < 1000; i++ {
+		key := "user-" + strconv.Itoa(i)
+		buf.Reset()
+		l.I.SampleKey(key, 0.25).Ln("first")
+		first := buf.Len() > 0
+		buf.Reset()
+		l.I.SampleKey(key, 0.25).Ln("second")
+		if first != (buf.Len() > 0) {
+			t.Fatalf("sampling of %s is not stable", key)
+		}
+		if first {
+			kept++
+		}
+	}
+	if kept < 150 || kept > 350 {
+		t.Fatalf("kept %d of 1000 keys at a rate of 0.25", kept)
+	}
+	if !l.I.SampleKey("any", 0).Chk(errors.New("dropped")) {
+		t.Fatalf("Chk on a dropped printer did not report the error")
+	}
+}
+
+func TestSample(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLogLevel(lol.Info)
+	hot := func(n int) {
+		for i := 0; i < n; i++ {
+			l.I.Sample(10).F("packet %d", i)
+		}
+	}
+	hot(25)
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Fatalf("wrote %d lines of 25 sampled one in 10:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), " packet 0 ") ||
+		!strings.Contains(buf.String(), " packet 20 ") {
+		t.Fatalf("unexpected lines kept:\n%s", buf.String())
+	}
+	buf.Reset()
+	for i := 0; i < 3; i++ {
+		l.I.Sample(10).Ln("other statement")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Fatalf("another statement did not sample on its own:\n%s", buf.String())
+	}
+	buf.Reset()
+	l.I.Sample(1).Ln("kept")
+	l.I.Sample(1).Ln("kept")
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Fatalf("Sample(1) dropped lines:\n%s", buf.String())
+	}
+}
+
+func TestClosures(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLogLevel(lol.Info)
+	called := 0
+	format := func() (string, []interface{}) {
+		called++
+		return "%d peers", []interface{}{3}
+	}
+	value := func() interface{} {
+		called++
+		return struct{ Peers int }{3}
+	}
+	l.D.CF(format)
+	l.D.CS(value)
+	lol.GetNullPrinter().CF(format)
+	lol.GetNullPrinter().CS(value)
+	if called != 0 || buf.Len() != 0 {
+		t.Fatalf("closures of disabled printers were called")
+	}
+	l.I.CF(format)
+	l.I.CS(value)
+	if called != 2 || !strings.Contains(buf.String(), " 3 peers ") ||
+		!strings.Contains(buf.String(), "Peers: (int) 3") {
+		t.Fatalf("unexpected lines %q", buf.String())
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLogLevel(lol.Info)
+	deploy, done := l.I.Group("deploy")
+	build, built := deploy.Group("build")
+	build.Ln("compiling")
+	built()
+	deploy.F("pushing %s", "image")
+	done()
+	l.I.Ln("after")
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		msg := strings.SplitN(line, " INF ", 2)[1]
+		got = append(got, msg[:strings.LastIndex(msg, " ")])
+	}
+	want := []string{"deploy", "  build", "    compiling", "  build done",
+		"  pushing image", "deploy done", "after"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got lines %q, want %q", got, want)
+	}
+	if strings.Count(buf.String(), "log_test.go:") != len(want) {
+		t.Fatalf("locations are not of the callers:\n%s", buf.String())
+	}
+}
+
+// logError is a helper wrapping a printer, as projects commonly have.
+func logError(l *lol.Log, a ...interface{}) { l.E.Skip(1).Ln(a...) }
+
+func TestSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLogLevel(lol.Info)
+	_, _, line, _ := runtime.Caller(0)
+	logError(l, "failed")
+	if want := fmt.Sprintf("log_test.go:%d", line+1); !strings.Contains(
+		buf.String(), want) {
+		t.Fatalf("line %q is not at the caller of the helper, %s",
+			buf.String(), want)
+	}
+}
+
+func TestRaw1(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	prev := lol.GetLogLevel()
+	defer lol.SetLogLevel(prev)
+	lol.SetLogLevel(lol.Info)
+	l.D.Raw1("gated")
+	if buf.Len() != 0 {
+		t.Fatalf("debug line written at info: %q", buf.String())
+	}
+	l.I.Raw1("pre-formatted %d")
+	if !strings.Contains(buf.String(), " pre-formatted %d ") ||
+		!strings.Contains(buf.String(), "log_test.go:") {
+		t.Fatalf("unexpected raw line %q", buf.String())
+	}
+}
+
+func TestErrCode(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.RegisterErrorCode(404, "not found")
+	e := l.E.ErrCode(404, "no user %q", "bob")
+	if !strings.Contains(buf.String(), `[code 404: not found] no user "bob"`) {
+		t.Fatalf("unexpected line %q", buf.String())
+	}
+	var coded interface{ Code() int }
+	if !errors.As(e, &coded) || coded.Code() != 404 ||
+		e.Error() != `no user "bob"` {
+		t.Fatalf("unexpected error %v", e)
+	}
+	buf.Reset()
+	e = l.E.ErrCode(599, "wrapped: %w", io.EOF)
+	if !strings.Contains(buf.String(), "[code 599] wrapped: EOF") ||
+		!errors.Is(e, io.EOF) {
+		t.Fatalf("unregistered code: line %q, error %v", buf.String(), e)
+	}
+}
+
+func TestChkE(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	if l.E.ChkE(nil) != nil || buf.Len() != 0 {
+		t.Fatalf("nil error was not passed through silently: %q", buf.String())
+	}
+	e := l.E.ChkE(io.EOF)
+	if !errors.Is(e, io.EOF) || errors.Unwrap(e) != io.EOF ||
+		!strings.HasSuffix(e.Error(), ": EOF") ||
+		!strings.Contains(e.Error(), "log_test.go:") ||
+		!strings.HasSuffix(buf.String(),
+			" EOF "+strings.TrimSuffix(e.Error(), ": EOF")+"\n") {
+		t.Fatalf("line %q, error %v", buf.String(), e)
+	}
+}
+
+func TestErrw(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	e := l.E.Errw(io.EOF, "reading %s", "config")
+	if !strings.Contains(buf.String(), " reading config: EOF ") ||
+		!errors.Is(e, io.EOF) || e.Error() != "reading config: EOF" {
+		t.Fatalf("line %q, error %v", buf.String(), e)
+	}
+	if e = l.E.Errw(nil, "no %s", "cause"); e.Error() != "no cause" {
+		t.Fatalf("unexpected error %v without a wrapped error", e)
+	}
+}
+
+func TestLocModuleVersion(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLocModuleVersion(true)
+	l.I.Ln("versioned")
+	lol.SetLocModuleVersion(false)
+	if !strings.Contains(buf.String(), "github.com/mleku/lol@") {
+		t.Fatalf("module version missing from %q", buf.String())
+	}
+}
+
+type spanKey struct{}
+
+func TestTraceSampler(t *testing.T) {
+	skipInRelease(t)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	prev := lol.GetLogLevel()
+	defer lol.SetLogLevel(prev)
+	lol.SetLogLevel(lol.Trace)
+	lol.SetTraceSampler(func(ctx context.Context) (sampled, present bool) {
+		sampled, present = ctx.Value(spanKey{}).(bool)
+		return
+	})
+	defer lol.SetTraceSampler(nil)
+	unsampled := context.WithValue(context.Background(), spanKey{}, false)
+	l.D.Ctx(unsampled).Ln("dropped")
+	l.T.Ctx(unsampled).Ln("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("verbose lines kept for an unsampled span: %q", buf.String())
+	}
+	l.I.Ctx(unsampled).Ln("info kept")
+	l.D.Ctx(unsampled).As(lol.Warn).Ln("raised kept")
+	l.D.Ctx(context.WithValue(context.Background(), spanKey{}, true)).
+		Ln("sampled kept")
+	l.D.Ctx(context.Background()).Ln("no span kept")
+	for _, want := range []string{"info kept", "raised kept", "sampled kept",
+		"no span kept"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("%q missing from %q", want, buf.String())
+		}
+	}
+}
+
+func TestChkIgnore(t *testing.T) {
+	var buf bytes.Buffer
+	l, chk := lol.New(&buf)
+	lol.SetChkIgnore(io.EOF)
+	defer lol.SetChkIgnore()
+	if !chk.E(fmt.Errorf("read: %w", io.EOF)) || !l.W.Chk(io.EOF) {
+		t.Fatalf("ignored error not reported to the caller")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("ignored error logged: %q", buf.String())
+	}
+	if !chk.E(io.ErrUnexpectedEOF) || !strings.Contains(buf.String(),
+		"unexpected EOF") {
+		t.Fatalf("other error not logged: %q", buf.String())
+	}
+}
+
+func TestIDGenerator(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	a, b := lol.TraceID(lol.WithTraceID(context.Background())),
+		lol.TraceID(lol.WithTraceID(context.Background()))
+	if len(a) != 26 || a == b {
+		t.Fatalf("unexpected default IDs %q and %q", a, b)
+	}
+	var n int
+	lol.SetIDGenerator(func() string {
+		n++
+		return "id-" + strconv.Itoa(n)
+	})
+	defer lol.SetIDGenerator(nil)
+	ctx := lol.WithTraceID(context.Background())
+	l.I.Ctx(ctx).Ln("traced")
+	l.I.Ctx(ctx).Ln("again")
+	if n != 1 || strings.Count(buf.String(), " trace_id=id-1 ") != 2 {
+		t.Fatalf("generator called %d times, lines %q", n, buf.String())
+	}
+}
+
+func TestColor(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.Ln("plain")
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("buffer written in color: %q", buf.String())
+	}
+	lol.SetColor(true)
+	defer lol.SetColor(false)
+	buf.Reset()
+	l.I.Ln("tinted")
+	out := buf.String()
+	if strings.Count(out, "\x1b[0m") != 2 ||
+		!strings.Contains(out, "\x1b[0m tinted \x1b[") {
+		t.Fatalf("not only the level and location colored: %q", out)
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	at := time.Date(2024, 1, 15, 10, 4, 5, 678000000, time.UTC)
+	lol.SetClock(func() time.Time { return at })
+	defer lol.SetClock(nil)
+	lol.SetTimeFormat("2006-01-02T15:04:05.000Z07:00")
+	defer lol.SetTimeFormatter(nil)
+	l.I.Ln("stamped")
+	if !strings.HasPrefix(buf.String(), "2024-01-15T10:04:05.678Z INF stamped ") {
+		t.Fatalf("unexpected line %q", buf.String())
+	}
+	lol.SetTimeFormat("")
+	buf.Reset()
+	l.I.Ln("bare")
+	if !strings.HasPrefix(buf.String(), "INF bare ") {
+		t.Fatalf("timestamp not left off %q", buf.String())
+	}
+}
+
+func TestExitFunc(t *testing.T) {
+	w := &flushWriter{}
+	l, chk := lol.New(w)
+	exitCodes = nil
+	l.E.Ln("error")
+	lol.GetPrinter(lol.Fatal, w).Ln("fatal without a Log")
+	if len(exitCodes) != 0 {
+		t.Fatalf("exited for %q", w.String())
+	}
+	l.F.Ln("fatal")
+	chk.F(errors.New("fatal check"))
+	if len(exitCodes) != 2 || exitCodes[0] != 1 || w.flushed != 3 ||
+		!strings.Contains(w.String(), "fatal check") {
+		t.Fatalf("exits %v after %d flushes of %q", exitCodes, w.flushed,
+			w.String())
+	}
+}
+
+func TestWith(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	req := l.I.With("request_id", "r1")
+	req.With("user", "bob").F("hello %s", "there")
+	req.Ln("again")
+	l.I.Ln("plain")
+	l.D.With("request_id", "r1").Ln("hidden")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 ||
+		!strings.Contains(lines[0], " hello there request_id=r1 user=bob ") ||
+		!strings.Contains(lines[1], " again request_id=r1 /") ||
+		!strings.Contains(lines[2], " plain /") {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	l, _ := lol.New(io.Discard)
+	if !l.I.Enabled() || l.D.Enabled() || (lol.LevelPrinter{}).Enabled() {
+		t.Fatalf("Enabled does not follow the log level")
+	}
+	if n := testing.AllocsPerRun(100, func() { l.T.Enabled() }); n != 0 {
+		t.Fatalf("Enabled allocates %v times for a disabled printer", n)
+	}
+}
+
+func TestLocFormat(t *testing.T) {
+	defer lol.SetLocFormat(lol.LocFull)
+	for _, c := range []struct {
+		format int
+		want   string
+	}{
+		{lol.LocFull, "/log_test.go:"},
+		{lol.LocPackage, " github.com/mleku/lol/log_test.go:"},
+		{lol.LocShort, " log_test.go:"},
+	} {
+		lol.SetLocFormat(c.format)
+		var buf bytes.Buffer
+		l, _ := lol.New(&buf)
+		l.I.Ln("here")
+		if !strings.Contains(buf.String(), c.want) ||
+			!strings.Contains(lol.GetLoc(1), strings.TrimSpace(c.want)) {
+			t.Fatalf("format %d: line %q, GetLoc %q", c.format, buf.String(),
+				lol.GetLoc(1))
+		}
+	}
+}
+
+func TestJoinStringsSep(t *testing.T) {
+	if got := lol.JoinStringsSep(",", "id", 42, 1.5); got != "id,42,1.5" {
+		t.Fatalf("unexpected joined values %q", got)
+	}
+	if got := lol.JoinStrings("id", 42, 1.5); got != "id 42 1.5" {
+		t.Fatalf("unexpected joined values %q", got)
+	}
+	if got := lol.JoinStringsSep("\t"); got != "" {
+		t.Fatalf("unexpected join of nothing %q", got)
+	}
+}
+
+func TestSpewConfig(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	v := &node{"a", &node{"b", &node{"c", nil}}}
+	cs := &spew.ConfigState{Indent: " ", MaxDepth: 1,
+		DisablePointerAddresses: true}
+	lol.SetSpewConfig(cs)
+	defer lol.SetSpewConfig(nil)
+	cs.MaxDepth = 0
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.S(v)
+	if strings.Contains(buf.String(), "0x") ||
+		strings.Contains(buf.String(), `"b"`) ||
+		!strings.Contains(buf.String(), `"a"`) {
+		t.Fatalf("settings not used: %s", buf.String())
+	}
+}
+
+func TestMaxMessageLen(t *testing.T) {
+	lol.SetMaxMessageLen(5)
+	defer lol.SetMaxMessageLen(0)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.F("%s", "abcdéfgh")
+	l.I.Ln("short")
+	if !strings.Contains(buf.String(),
+		" INF abcd…(truncated, total 9 bytes) ") ||
+		!strings.Contains(buf.String(), " INF short ") {
+		t.Fatalf("unexpected lines %q", buf.String())
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	lol.SetRelativeTime(true)
+	defer lol.SetRelativeTime(false)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.Ln("relative")
+	if ok, _ := regexp.MatchString(`^\+\d+\.\d{3}s INF relative `,
+		buf.String()); !ok {
+		t.Fatalf("unexpected line %q", buf.String())
+	}
+	lol.SetTimeFormat("2006")
+	buf.Reset()
+	l.I.Ln("absolute")
+	if strings.HasPrefix(buf.String(), "+") {
+		t.Fatalf("relative time not replaced: %q", buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package lol
+
+import "bytes"
+
+// LogError is the error returned by Err and Errw, which carries what was
+// logged with it, so that code further up, such as an HTTP handler, can send
+// it on as a JSON error response. Its Error is the formatted message, led by
+// the code location when SetErrIncludeLoc is on, and it unwraps to the error
+// wrapped by Errw.
+type LogError struct {
+	// Msg is the formatted message
+	Msg string
+	// Loc is the code location of the caller of Err or Errw
+	Loc string
+	// Level is the full name of the level of the printer
+	Level string
+	// Fields are the fields of the printer, from With and Ctx
+	Fields []Field
+	err    error
+	// cfg is the config it was made with, which it is rendered with, nil for
+	// errors made elsewhere.
+	cfg *config
+}
+
+func (e *LogError) Error() string {
+	if e.cfg != nil && e.cfg.errIncludeLoc {
+		return e.Loc + locSep + e.err.Error()
+	}
+	return e.err.Error()
+}
+
+func (e *LogError) Unwrap() error { return e.err }
+
+// MarshalJSON renders e as an object with the keys msg, loc and level, and
+// fields when it has any, in the manner of JSONEncoder, masked and scrubbed with
+// the settings from when it was made.
+func (e *LogError) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`{"msg":`)
+	jsonValue(&b, e.Msg)
+	b.WriteString(`,"loc":`)
+	jsonValue(&b, e.Loc)
+	b.WriteString(`,"level":`)
+	jsonValue(&b, e.Level)
+	c := e.cfg
+	if c == nil {
+		c = loadConfig()
+	}
+	if fields, _ := c.encodeFields(e.Fields); len(fields) > 0 {
+		b.WriteString(`,"fields":`)
+		jsonFields(&b, fields)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package lol_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestLogError(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	err := l.W.With("user", "bob").Err("no access to %s", "/admin")
+	if err.Error() != "no access to /admin" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	var le *lol.LogError
+	if !errors.As(err, &le) {
+		t.Fatalf("Err returned a %T, not a *LogError", err)
+	}
+	j, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	if !strings.HasPrefix(string(j), `{"msg":"no access to /admin","loc":"`) ||
+		!strings.Contains(string(j), `logerror_test.go:18","level":"warn",`+
+			`"fields":{"user":"bob"}}`) {
+		t.Fatalf("unexpected JSON %s", j)
+	}
+	err = l.E.Errw(io.EOF, "read %s", "config")
+	if !errors.Is(err, io.EOF) || err.Error() != "read config: EOF" ||
+		!errors.As(err, &le) || le.Msg != "read config: EOF" {
+		t.Fatalf("unexpected wrapping error %v", err)
+	}
+}
+
+func TestLogErrorConfig(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetMaskedKeys("token")
+	err := l.E.With("token", "s3cret").Err("denied")
+	lol.SetMaskedKeys()
+	j, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	if strings.Contains(string(j), "s3cret") {
+		t.Fatalf("field masked when the error was made was not: %s", j)
+	}
+}
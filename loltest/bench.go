@@ -0,0 +1,47 @@
+// Package loltest holds helpers for testing and benchmarking programs that
+// log with lol, kept apart so that the programs themselves do not link the
+// testing package.
+package loltest
+
+import (
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+// RunOverheadBench measures what each call to the Ln, F and S printers of l
+// costs at Info, as sub-benchmarks of b with allocations reported, so that a
+// program can check its own setup of writer, encoder and settings against its
+// budget:
+//
+//	func BenchmarkLog(b *testing.B) {
+//		l, _ := lol.NewWithEncoder(io.Discard, lol.JSONEncoder)
+//		loltest.RunOverheadBench(b, l)
+//	}
+//
+// Writers that implement Flusher, such as asynchronous ones, are flushed
+// within the timing, so that lines still queued count towards it. Lines are
+// filtered out as usual, so with Info below the log level it measures the
+// cost of a disabled printer.
+func RunOverheadBench(b *testing.B, l *lol.Log) {
+	v := struct {
+		Name string
+		N    int
+	}{"bench", 42}
+	for _, bench := range []struct {
+		name string
+		call func(i int)
+	}{
+		{"Ln", func(i int) { l.I.Ln("overhead", i, 1.5) }},
+		{"F", func(i int) { l.I.F("overhead %d %0.1f", i, 1.5) }},
+		{"S", func(i int) { l.I.S(v) }},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bench.call(i)
+			}
+			_ = l.Flush()
+		})
+	}
+}
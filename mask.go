@@ -0,0 +1,54 @@
+package lol
+
+import (
+	"strings"
+)
+
+// Masked is what encoders print in place of the value of a masked field.
+const Masked = "***"
+
+// SetMaskedKeys sets field keys, such as password or ssn, whose values are
+// always replaced with Masked when an entry is encoded, however the field was
+// added. Keys match case insensitively, including keys inside field values
+// that are themselves maps of fields. Calling it with no keys masks nothing.
+func SetMaskedKeys(keys ...string) {
+	m := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		m[strings.ToLower(k)] = struct{}{}
+	}
+	update(func(c *config) { c.maskedKeys = m })
+}
+
+// maskFields returns fields with the values of the keys in m replaced, leaving
+// fields itself untouched.
+func maskFields(m map[string]struct{}, fields []Field) []Field {
+	if len(m) == 0 || len(fields) == 0 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{f.Key, maskValue(m, f.Key, f.Value)}
+	}
+	return out
+}
+
+func maskValue(m map[string]struct{}, key string, v interface{}) interface{} {
+	if _, ok := m[strings.ToLower(key)]; ok {
+		return Masked
+	}
+	switch nested := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(nested))
+		for k, nv := range nested {
+			out[k] = maskValue(m, k, nv)
+		}
+		return out
+	case []Field:
+		out := make([]Field, len(nested))
+		for i, f := range nested {
+			out[i] = Field{f.Key, maskValue(m, f.Key, f.Value)}
+		}
+		return out
+	}
+	return v
+}
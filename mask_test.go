@@ -0,0 +1,180 @@
+package lol_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestMaskedKeys(t *testing.T) {
+	lol.SetMaskedKeys("Password", "ssn")
+	defer lol.SetMaskedKeys()
+	e := &lol.Entry{
+		LevelID: lol.Info,
+		Text:    "login",
+		Fields: []lol.Field{
+			{"user", "bob"},
+			{"PASSWORD", "hunter2"},
+			{"profile", map[string]interface{}{"SSN": "078-05-1120"}},
+		},
+	}
+	got := string(lol.TextEncoder(e))
+	if strings.Contains(got, "hunter2") || strings.Contains(got, "078-05-1120") {
+		t.Fatalf("masked values leaked in %q", got)
+	}
+	if !strings.Contains(got, "user=bob") ||
+		!strings.Contains(got, "PASSWORD=***") {
+		t.Fatalf("unexpected fields in %q", got)
+	}
+	if e.Fields[1].Value != "hunter2" {
+		t.Fatalf("masking modified the entry")
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	lol.SetMaxFields(2)
+	defer lol.SetMaxFields(0)
+	e := &lol.Entry{Text: "many", Fields: []lol.Field{
+		{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}}}
+	if got := string(lol.TextEncoder(e)); !strings.Contains(got,
+		"many a=1 b=2 ...(+2 fields) ") {
+		t.Fatalf("fields not capped in %q", got)
+	}
+	if got := string(lol.JSONEncoder(e)); !strings.Contains(got,
+		`"fields":{"a":1,"b":2},"dropped_fields":2`) {
+		t.Fatalf("fields not capped in %s", got)
+	}
+}
+
+func TestKV(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.I.KV("", "event", "login", "user", 7, "dangling")
+	if !strings.Contains(buf.String(),
+		lol.LevelSpecs[lol.Info].Name+" event=login user=7 dangling=(missing) ") {
+		t.Fatalf("unexpected fields only line %q", buf.String())
+	}
+	buf.Reset()
+	jl, _ := lol.NewWithEncoder(&buf, lol.JSONEncoder)
+	jl.I.KV("signed in", "user", 7)
+	if !strings.Contains(buf.String(),
+		`"msg":"signed in","fields":{"user":7}`) {
+		t.Fatalf("unexpected JSON %s", buf.String())
+	}
+}
+
+func TestScrubPII(t *testing.T) {
+	for in, want := range map[string]string{
+		"mail bob.smith+x@mail.example.co.uk now": "mail [email] now",
+		"from 10.0.0.12:8080":                     "from [ip]:8080",
+		"peer fe80::1ff:fe23:4567:890a up":        "peer [ip] up",
+		"peer 2001:db8:0:0:0:ff00:42:8329":        "peer [ip]",
+		"at 2001:db8::1 and ::ffff:a00:1":         "at [ip] and [ip]",
+		"to 2001:db8:: now":                       "to [ip] now",
+		"std::vector and a::b":                    "std::vector and a::b",
+		"Foo::Bar, cafe::beef and ::1":            "Foo::Bar, cafe::beef and ::1",
+		"call +1 555 123 4567 or (555) 123-4567":  "call [phone] or [phone]",
+		"at 1791997501.079451675 took 12:30:05":   "at 1791997501.079451675 took 12:30:05",
+		"on 2024-10-14 v1.22.4":                   "on 2024-10-14 v1.22.4",
+	} {
+		if got := lol.ScrubPII(in); got != want {
+			t.Errorf("ScrubPII(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPIIScrubber(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.NewWithEncoder(&buf, lol.JSONEncoder)
+	lol.SetPIIScrubber(lol.ScrubPII)
+	defer lol.SetPIIScrubber(nil)
+	l.I.KV("signup from bob@example.com", "note", "call 555-123-4567",
+		"n", 5)
+	if got := buf.String(); !strings.Contains(got,
+		`"msg":"signup from [email]","fields":{"note":"call [phone]","n":5}`) {
+		t.Fatalf("not scrubbed: %s", got)
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetFieldOrder("request_id", "user")
+	defer lol.SetFieldOrder()
+	l.I.KV("served", "path", "/", "user", "bob", "code", 200,
+		"request_id", "r-1")
+	if !strings.Contains(buf.String(),
+		"served request_id=r-1 user=bob code=200 path=/ ") {
+		t.Fatalf("fields not in order: %q", buf.String())
+	}
+}
+
+type credentials struct {
+	User     string
+	Password string
+	apiKey   []byte
+	Nested   *credentials
+	Extra    map[string]interface{}
+	Tokens   []credentials
+}
+
+func TestRedactFields(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	v := credentials{
+		User:     "bob",
+		Password: "hunter2",
+		apiKey:   []byte("k3y"),
+		Nested:   &credentials{Password: "inner"},
+		Extra:    map[string]interface{}{"token": "t0k", "ok": 1},
+		Tokens:   []credentials{{Password: "listed"}},
+	}
+	l.I.S(v)
+	if !strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("redacted without names set: %s", buf.String())
+	}
+	lol.SetRedactFields("password", "APIKEY", "Token")
+	defer lol.SetRedactFields()
+	buf.Reset()
+	l.I.S(v, &v)
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "inner", "t0k", "listed",
+		"k3y", "6b 33 79"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("%q not redacted: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `"bob"`) || !strings.Contains(out, `"***"`) {
+		t.Fatalf("unexpected dump: %s", out)
+	}
+	if v.Password != "hunter2" || v.Nested.Password != "inner" ||
+		v.Extra["token"] != "t0k" || string(v.apiKey) != "k3y" {
+		t.Fatalf("the value printed was changed: %+v", v)
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.SetRedactPatterns()
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	var hooked string
+	lol.AddHook(func(level int, loc, msg string) { hooked = msg })
+	defer lol.RemoveHooks()
+	lol.SetRedactPatterns(regexp.MustCompile(`Bearer \S+`),
+		regexp.MustCompile(`\b\d{13,16}\b`))
+	l.I.F("auth=%s card %s", "Bearer abc.def", "4111111111111111")
+	if want := "auth=*** card ***"; !strings.Contains(buf.String(),
+		" "+want+" ") || hooked != want {
+		t.Fatalf("not redacted: line %q, hook %q", buf.String(), hooked)
+	}
+	lol.SetRedactPatterns()
+	buf.Reset()
+	l.I.Ln("Bearer xyz")
+	if !strings.Contains(buf.String(), "Bearer xyz") {
+		t.Fatalf("redacted with no patterns: %q", buf.String())
+	}
+}
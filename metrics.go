@@ -0,0 +1,29 @@
+package lol
+
+import (
+	"go.uber.org/atomic"
+)
+
+// levelCounts counts the lines of each level that got past the level check.
+var levelCounts [Trace + 1]atomic.Uint64
+
+// Metrics returns how many lines have been logged at each level since the
+// start or the last ResetMetrics, keyed by the names of LevelNames from fatal
+// to trace, such as to export as a counter for each level. Lines are counted
+// once they are within the log level, including those from Chk and Err, and
+// those that are then held back by SetRateLimit, SetDedup or
+// SetAggregateWindow.
+func Metrics() map[string]uint64 {
+	m := make(map[string]uint64, Trace)
+	for level := Fatal; level <= Trace; level++ {
+		m[LevelNames[level]] = levelCounts[level].Load()
+	}
+	return m
+}
+
+// ResetMetrics sets the counts returned by Metrics back to zero.
+func ResetMetrics() {
+	for i := range levelCounts {
+		levelCounts[i].Store(0)
+	}
+}
@@ -0,0 +1,28 @@
+package lol_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestMetrics(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	lol.ResetMetrics()
+	l, _ := lol.New(io.Discard)
+	l.E.Chk(errors.New("failed"))
+	l.E.Chk(nil)
+	_ = l.E.Err("failed %d", 2)
+	l.W.Ln("careful")
+	l.D.Ln("not counted")
+	m := lol.Metrics()
+	if m["error"] != 2 || m["warn"] != 1 || m["debug"] != 0 || len(m) != 6 {
+		t.Fatalf("unexpected counts %v", m)
+	}
+	lol.ResetMetrics()
+	if lol.Metrics()["error"] != 0 {
+		t.Fatalf("counts not reset")
+	}
+}
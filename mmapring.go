@@ -0,0 +1,40 @@
+package lol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+)
+
+// mmapRingMagic starts every ring file so ReadMmapRing can tell it is one.
+var mmapRingMagic = []byte("LOLRING1")
+
+// mmapRingHeader is the magic followed by the little endian uint64 count of
+// bytes ever written, which locates the oldest byte once the ring has wrapped.
+const mmapRingHeader = 16
+
+// ReadMmapRing recovers the contents of a ring file written by MmapRingWriter,
+// oldest first, such as after the process that wrote it crashed. Once the ring
+// has wrapped the first line is usually cut short.
+func ReadMmapRing(path string) (b []byte, err error) {
+	var f []byte
+	if f, err = os.ReadFile(path); err != nil {
+		return
+	}
+	if len(f) < mmapRingHeader || !bytes.Equal(f[:8], mmapRingMagic) {
+		return nil, errors.New("lol: " + path + " is not a ring file")
+	}
+	data := f[mmapRingHeader:]
+	if len(data) == 0 {
+		return
+	}
+	total := binary.LittleEndian.Uint64(f[8:mmapRingHeader])
+	size := uint64(len(data))
+	if total <= size {
+		return append(b, data[:total]...), nil
+	}
+	head := total % size
+	b = append(b, data[head:]...)
+	return append(b, data[:head]...), nil
+}
@@ -0,0 +1,23 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lol
+
+import (
+	"errors"
+)
+
+// MmapRing is a fixed size ring of recent output in a memory mapped file, which
+// is not available on this platform.
+type MmapRing struct{}
+
+// MmapRingWriter is not supported on this platform and always fails.
+func MmapRingWriter(path string, size int) (r *MmapRing, err error) {
+	return nil, errors.New("lol: memory mapped rings are not supported here")
+}
+
+func (r *MmapRing) Write(b []byte) (n int, err error) {
+	return 0, errors.New("lol: memory mapped rings are not supported here")
+}
+
+// Close does nothing.
+func (r *MmapRing) Close() (err error) { return }
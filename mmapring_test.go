@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestMmapRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	r, err := lol.MmapRingWriter(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("abcdef"))
+	// simulate a crash by reading without closing
+	if got, err := lol.ReadMmapRing(path); err != nil || string(got) != "abcdef" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	r.Write([]byte("ghijkl"))
+	if got, _ := lol.ReadMmapRing(path); string(got) != "cdefghijkl" {
+		t.Fatalf("ring did not wrap, got %q", got)
+	}
+	r.Write([]byte("0123456789XY"))
+	if got, _ := lol.ReadMmapRing(path); string(got) != "23456789XY" {
+		t.Fatalf("oversized write not trimmed to the newest bytes, got %q", got)
+	}
+	r.Close()
+	r, err = lol.MmapRingWriter(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.Write([]byte("Z"))
+	if got, _ := lol.ReadMmapRing(path); string(got) != "3456789XYZ" {
+		t.Fatalf("reopened ring did not carry on, got %q", got)
+	}
+}
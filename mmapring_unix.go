@@ -0,0 +1,100 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// MmapRing is a fixed size ring of the most recent output kept in a memory
+// mapped file. Because the mapping is shared, what has been written is in the
+// page cache rather than the memory of the process, and survives the process
+// crashing.
+type MmapRing struct {
+	mx    sync.Mutex
+	f     *os.File
+	m     []byte
+	data  []byte
+	total uint64
+}
+
+// MmapRingWriter opens or creates the file at path as a ring holding the last
+// size bytes written to it, for use as the writer of a Log on devices where
+// logs must be recoverable after a crash, with ReadMmapRing. Writes wrap
+// around the end of the ring, overwriting the oldest output.
+func MmapRingWriter(path string, size int) (r *MmapRing, err error) {
+	if size <= 0 {
+		return nil, errors.New("lol: ring size must be positive")
+	}
+	var f *os.File
+	if f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return
+	}
+	length := mmapRingHeader + size
+	var fi os.FileInfo
+	if fi, err = f.Stat(); err != nil {
+		f.Close()
+		return
+	}
+	// a ring of another size cannot be carried on from
+	resume := fi.Size() == int64(length)
+	if err = f.Truncate(int64(length)); err != nil {
+		f.Close()
+		return
+	}
+	var m []byte
+	if m, err = syscall.Mmap(int(f.Fd()), 0, length,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED); err != nil {
+		f.Close()
+		return
+	}
+	r = &MmapRing{f: f, m: m, data: m[mmapRingHeader:]}
+	if resume && string(m[:8]) == string(mmapRingMagic) {
+		// carry on from where a previous process left off
+		r.total = binary.LittleEndian.Uint64(m[8:mmapRingHeader])
+	} else {
+		copy(m, mmapRingMagic)
+		binary.LittleEndian.PutUint64(m[8:mmapRingHeader], 0)
+	}
+	return
+}
+
+func (r *MmapRing) Write(b []byte) (n int, err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.m == nil {
+		return 0, os.ErrClosed
+	}
+	n = len(b)
+	size := uint64(len(r.data))
+	if uint64(len(b)) > size {
+		r.total += uint64(len(b)) - size
+		b = b[uint64(len(b))-size:]
+	}
+	for len(b) > 0 {
+		c := copy(r.data[r.total%size:], b)
+		b = b[c:]
+		r.total += uint64(c)
+	}
+	binary.LittleEndian.PutUint64(r.m[8:mmapRingHeader], r.total)
+	return
+}
+
+// Close unmaps and closes the ring file. What was written stays in the file.
+func (r *MmapRing) Close() (err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.m == nil {
+		return
+	}
+	err = syscall.Munmap(r.m)
+	r.m, r.data = nil, nil
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
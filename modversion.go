@@ -0,0 +1,74 @@
+package lol
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+var (
+	// packageModules caches the module@version found for each package path.
+	packageModules sync.Map
+	buildInfo      struct {
+		sync.Once
+		modules []*debug.Module
+	}
+)
+
+// SetLocModuleVersion puts the path and version of the module that owns the
+// logging code in front of its location, as in lib@v1.2.3 /src/file.go:42, to
+// tell which version of a dependency logged a line. Modules are found from the
+// build information of the binary, so it adds nothing when that is missing.
+func SetLocModuleVersion(enabled bool) {
+	update(func(c *config) { c.locModuleVersion = enabled })
+}
+
+// moduleOf returns module@version for the module owning the function at pc, or
+// nothing if it is not known.
+func moduleOf(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	pkg := packagePath(fn.Name())
+	if m, ok := packageModules.Load(pkg); ok {
+		return m.(string)
+	}
+	buildInfo.Do(func() {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildInfo.modules = append([]*debug.Module{&bi.Main}, bi.Deps...)
+	})
+	var best *debug.Module
+	for _, m := range buildInfo.modules {
+		if m.Path != "" && (pkg == m.Path || strings.HasPrefix(pkg, m.Path+"/")) &&
+			(best == nil || len(m.Path) > len(best.Path)) {
+			best = m
+		}
+	}
+	var s string
+	if best != nil {
+		version := best.Version
+		if best.Replace != nil && best.Replace.Version != "" {
+			version = best.Replace.Version
+		}
+		s = best.Path + "@" + version
+	}
+	packageModules.Store(pkg, s)
+	return s
+}
+
+// packagePath cuts the function and any receiver off a function name such as
+// github.com/a/b.(*T).M, leaving the import path of its package, with the
+// _test of an external test package dropped.
+func packagePath(funcName string) (pkg string) {
+	pkg = funcName
+	slash := strings.LastIndexByte(funcName, '/')
+	if dot := strings.IndexByte(funcName[slash+1:], '.'); dot >= 0 {
+		pkg = funcName[:slash+1+dot]
+	}
+	return strings.TrimSuffix(pkg, "_test")
+}
@@ -0,0 +1,111 @@
+package lol
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+// LeveledOutput is a writer for NewMulti along with the least severe level
+// of the lines it gets, so an output with Level Info gets Fatal, Error, Warn
+// and Info lines.
+type LeveledOutput struct {
+	Writer io.Writer
+	Level  int
+}
+
+// NewMulti returns a Log that writes each line to every one of outputs whose
+// level it is within, such as Info and above to a file while Debug and Trace
+// go to stderr as well. Lines are still filtered by the level from
+// SetLogLevel first. Each line is encoded once and the same bytes written to
+// each output, so it is only colored when every output is a terminal. An
+// output that fails does not stop the others from being written to, and the
+// errors of all that failed go to the handler from SetWriteErrorHandler
+// together.
+func NewMulti(outputs ...LeveledOutput) (l *Log, c *Check) {
+	outputs = append([]LeveledOutput(nil), outputs...)
+	l, c = newLog(printer{})
+	for _, lp := range l.printers() {
+		m := &multiWriter{}
+		for _, o := range outputs {
+			if int(lp.p.level) <= o.Level {
+				m.writers = append(m.writers, o.Writer)
+			}
+		}
+		lp.p.writer = m
+		lp.p.detectTerminal()
+	}
+	return
+}
+
+// NewSplit returns a Log that writes Fatal, Error and Warn lines to errw and
+// Info, Debug and Trace lines to out, as a command line program would write
+// diagnostics to stderr and the rest to stdout. The Chk and Err printers of
+// the levels write where their level does, so those of the Check for Fatal,
+// Error and Warn write to errw. skip is as for NewUnixgram.
+func NewSplit(out, errw io.Writer, skip int) (l *Log, c *Check) {
+	l, c = newLog(printer{skip: skip})
+	for _, lp := range l.printers() {
+		lp.p.writer = out
+		if lp.p.level <= Warn {
+			lp.p.writer = errw
+		}
+		lp.p.detectTerminal()
+	}
+	return
+}
+
+// printers returns the printers of l from Fatal to Trace, for constructors
+// that give them writers of their own once they are made.
+func (l *Log) printers() []LevelPrinter {
+	return []LevelPrinter{l.F, l.E, l.W, l.I, l.D, l.T}
+}
+
+// Flush flushes each of the writers of the printers of l that is a Flusher,
+// such as an asynchronous writer, once, returning their errors together.
+func (l *Log) Flush() error {
+	var flushed []io.Writer
+	var errs []error
+	for _, lp := range l.printers() {
+		if lp.p == nil || slices.ContainsFunc(flushed, func(w io.Writer) bool {
+			return sameWriter(w, lp.p.writer)
+		}) {
+			continue
+		}
+		flushed = append(flushed, lp.p.writer)
+		if f, ok := lp.p.writer.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// multiWriter writes to each of writers in turn.
+type multiWriter struct{ writers []io.Writer }
+
+func (m *multiWriter) Write(b []byte) (n int, err error) {
+	var errs []error
+	for _, w := range m.writers {
+		if _, err := w.Write(b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(b), errors.Join(errs...)
+}
+
+// Flush flushes each of the writers that is a Flusher.
+func (m *multiWriter) Flush() error {
+	var errs []error
+	for _, w := range m.writers {
+		if f, ok := w.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiWriter) wrapped() []io.Writer { return m.writers }
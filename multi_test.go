@@ -0,0 +1,71 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestMulti(t *testing.T) {
+	skipInRelease(t)
+	lol.SetLogLevel(lol.Trace)
+	var errs []error
+	lol.SetWriteErrorHandler(func(err error) { errs = append(errs, err) })
+	defer func() {
+		lol.SetLogLevel(lol.Info)
+		lol.SetWriteErrorHandler(nil)
+	}()
+	var file, stderr bytes.Buffer
+	l, _ := lol.NewMulti(
+		lol.LeveledOutput{Writer: failingWriter{}, Level: lol.Error},
+		lol.LeveledOutput{Writer: &file, Level: lol.Info},
+		lol.LeveledOutput{Writer: &stderr, Level: lol.Trace},
+	)
+	l.W.Ln("warning")
+	l.T.Ln("tracing")
+	if file.String() == "" || !strings.Contains(file.String(), " warning ") ||
+		strings.Contains(file.String(), "tracing") {
+		t.Fatalf("unexpected lines in the file: %q", file.String())
+	}
+	if file.String() != strings.SplitAfter(stderr.String(), "\n")[0] ||
+		!strings.Contains(stderr.String(), " tracing ") {
+		t.Fatalf("unexpected lines on stderr: %q", stderr.String())
+	}
+	l.E.Ln("failing")
+	if len(errs) != 1 || !strings.Contains(file.String(), " failing ") {
+		t.Fatalf("errors %v, file %q", errs, file.String())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var out, errw bytes.Buffer
+	l, c := lol.NewSplit(&out, &errw, 0)
+	l.I.Ln("result")
+	l.W.Ln("careful")
+	c.E(errors.New("failed"))
+	if !strings.Contains(out.String(), " result ") ||
+		strings.Contains(out.String(), "careful") ||
+		!strings.Contains(errw.String(), " careful ") ||
+		!strings.Contains(errw.String(), " failed ") ||
+		strings.Contains(errw.String(), "result") {
+		t.Fatalf("out %q, errw %q", out.String(), errw.String())
+	}
+}
+
+func TestLogFlush(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf lockedBuffer
+	w, _ := lol.NewAsyncWriter(&buf, 16)
+	defer w.Close()
+	l, _ := lol.NewSplit(w, w, 0)
+	l.I.Ln("queued")
+	l.E.Ln("queued too")
+	if err := l.Flush(); err != nil ||
+		strings.Count(buf.String(), "queued") != 2 {
+		t.Fatalf("lines not written by Flush: %v %q", err, buf.String())
+	}
+}
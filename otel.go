@@ -0,0 +1,126 @@
+package lol
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OtelRecord is a log record in the OpenTelemetry log data model, as
+// NewOtelWriter makes from each entry, for an OtelExporter to convert into the
+// records of the SDK it ships them with.
+type OtelRecord struct {
+	Timestamp time.Time
+	// SeverityNumber is the OpenTelemetry severity of the level, from
+	// OtelSeverity
+	SeverityNumber int
+	// SeverityText is the full name of the level, in upper case
+	SeverityText string
+	// Body is the message, passed through the scrubber from SetPIIScrubber
+	Body string
+	// TraceID is the ID from WithTraceID, when the entry has one
+	TraceID string
+	// Attributes are the fields of the entry, among them the correlation_id
+	// of a printer from LevelPrinter.Ctx, masked, limited and scrubbed as the
+	// encoders do, with dropped_fields when SetMaxFields cut some, followed by
+	// its code location as code.filepath, code.lineno and code.function, and
+	// its detail, such as a stack, as detail
+	Attributes []Field
+}
+
+// OtelExporter ships log records to an OpenTelemetry collector, in the manner
+// of the Exporter of the OpenTelemetry log SDK, which can be adapted to it.
+type OtelExporter interface {
+	Export(ctx context.Context, records []OtelRecord) error
+}
+
+// otelWriter is the writer returned by NewOtelWriter.
+type otelWriter struct{ exporter OtelExporter }
+
+// NewOtelWriter returns a writer for New that hands each entry to exporter as
+// an OtelRecord, in place of encoding it, for an observability stack built on
+// OpenTelemetry. Lines written to it by other means are read as records of
+// JSONEncoder, or else taken as the body of an Info record. Errors from
+// exporter go to the handler from SetWriteErrorHandler. With a nil exporter it
+// discards everything written to it.
+func NewOtelWriter(exporter OtelExporter) io.Writer {
+	return &otelWriter{exporter: exporter}
+}
+
+// OtelSeverity returns the OpenTelemetry severity number of level, which is
+// the first of the range for the matching severity, or 0, for unspecified,
+// for Off and anything outside the levels.
+func OtelSeverity(level int) int {
+	switch level {
+	case Fatal:
+		return 21
+	case Error:
+		return 17
+	case Warn:
+		return 13
+	case Info:
+		return 9
+	case Debug:
+		return 5
+	case Trace:
+		return 1
+	}
+	return 0
+}
+
+func (o *otelWriter) Write(b []byte) (n int, err error) {
+	e, perr := ParseRecord(b)
+	if perr != nil {
+		e = Entry{Time: loadConfig().now(), LevelID: Info,
+			Text: strings.TrimSuffix(string(b), "\n")}
+	}
+	return len(b), o.writeEntry(&e)
+}
+
+func (o *otelWriter) writeEntry(e *Entry) error {
+	if o.exporter == nil {
+		return nil
+	}
+	c := e.config()
+	r := OtelRecord{
+		Timestamp:      e.Time,
+		SeverityNumber: OtelSeverity(e.LevelID),
+		Body:           c.scrubText(e.Text),
+	}
+	fields, dropped := c.encodeFields(e.Fields)
+	r.Attributes = make([]Field, 0, len(fields)+5)
+	if e.LevelID >= Off && e.LevelID <= Trace {
+		r.SeverityText = strings.ToUpper(LevelNames[e.LevelID])
+	}
+	for _, f := range fields {
+		if f.Key == "trace_id" {
+			if id, ok := f.Value.(string); ok {
+				r.TraceID = id
+				continue
+			}
+		}
+		r.Attributes = append(r.Attributes, f)
+	}
+	if dropped > 0 {
+		r.Attributes = append(r.Attributes, Field{"dropped_fields", dropped})
+	}
+	if e.CodeLocation != "" {
+		file, line := e.CodeLocation, ""
+		if i := strings.LastIndexByte(file, ':'); i >= 0 {
+			file, line = file[:i], file[i+1:]
+		}
+		r.Attributes = append(r.Attributes, Field{"code.filepath", file})
+		if n, err := strconv.Atoi(line); err == nil {
+			r.Attributes = append(r.Attributes, Field{"code.lineno", n})
+		}
+	}
+	if e.Function != "" {
+		r.Attributes = append(r.Attributes, Field{"code.function", e.Function})
+	}
+	if e.Detail != "" {
+		r.Attributes = append(r.Attributes, Field{"detail", e.Detail})
+	}
+	return o.exporter.Export(context.Background(), []OtelRecord{r})
+}
@@ -0,0 +1,64 @@
+package lol_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+type recordingExporter struct{ records []lol.OtelRecord }
+
+func (r *recordingExporter) Export(ctx context.Context,
+	records []lol.OtelRecord) error {
+
+	r.records = append(r.records, records...)
+	return nil
+}
+
+func TestOtelWriter(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	exp := &recordingExporter{}
+	l, _ := lol.New(lol.NewOtelWriter(exp))
+	ctx := lol.WithContext(lol.WithTraceID(context.Background()), "req-1")
+	l.W.Ctx(ctx).F("slow query %dms", 1200)
+	if len(exp.records) != 1 {
+		t.Fatalf("exported %d records", len(exp.records))
+	}
+	r := exp.records[0]
+	attrs := map[string]interface{}{}
+	for _, f := range r.Attributes {
+		attrs[f.Key] = f.Value
+	}
+	if r.SeverityNumber != 13 || r.SeverityText != "WARN" ||
+		r.Body != "slow query 1200ms" || r.TraceID == "" ||
+		attrs["correlation_id"] != "req-1" || attrs["code.lineno"] != 25 ||
+		!strings.HasSuffix(attrs["code.filepath"].(string), "otel_test.go") {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	l, _ = lol.New(lol.NewOtelWriter(nil))
+	l.E.Ln("nowhere")
+}
+
+func TestOtelWriterScrubbing(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	lol.SetMaskedKeys("password")
+	defer lol.SetMaskedKeys()
+	lol.SetMaxFields(2)
+	defer lol.SetMaxFields(0)
+	lol.SetPIIScrubber(lol.ScrubPII)
+	defer lol.SetPIIScrubber(nil)
+	exp := &recordingExporter{}
+	l, _ := lol.New(lol.NewOtelWriter(exp))
+	l.I.With("password", "hunter2", "a", 1, "b", 2).Ln("mail bob@example.com")
+	r := exp.records[0]
+	attrs := map[string]interface{}{}
+	for _, f := range r.Attributes {
+		attrs[f.Key] = f.Value
+	}
+	if r.Body != "mail [email]" || attrs["password"] != lol.Masked ||
+		attrs["a"] != 1 || attrs["b"] != nil || attrs["dropped_fields"] != 1 {
+		t.Fatalf("record not masked, limited and scrubbed: %+v", r)
+	}
+}
@@ -0,0 +1,170 @@
+package lol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// passthroughLevels maps level names that other loggers commonly emit onto
+// the levels here.
+var passthroughLevels = map[string]int{
+	"fatal": Fatal, "panic": Fatal, "critical": Fatal, "crit": Fatal,
+	"ftl": Fatal, "error": Error, "err": Error, "warn": Warn,
+	"warning": Warn, "wrn": Warn, "info": Info, "inf": Info,
+	"notice": Info, "debug": Debug, "dbg": Debug, "trace": Trace,
+	"trc": Trace,
+}
+
+// passthroughMessageKeys are the keys other loggers put the message under.
+var passthroughMessageKeys = []string{"msg", "message"}
+
+type jsonPassthrough struct {
+	sync.Mutex
+	l       *Log
+	partial []byte
+}
+
+// JSONPassthroughWriter returns a writer for the output of sub-processes that
+// log JSON, such as the Stdout of an exec.Cmd. Each line is parsed as a JSON
+// object and logged through l at the level named by its level key, with its
+// msg or message as the text and its other keys as fields in key order. Lines
+// that are not JSON objects are logged verbatim at Info. Lines are filtered by
+// the log level as those of l are. A final line without a newline is held
+// until Close.
+func JSONPassthroughWriter(l *Log) io.WriteCloser {
+	return &jsonPassthrough{l: l}
+}
+
+func (j *jsonPassthrough) Write(b []byte) (n int, err error) {
+	j.Lock()
+	defer j.Unlock()
+	j.partial = append(j.partial, b...)
+	for {
+		i := bytes.IndexByte(j.partial, '\n')
+		if i < 0 {
+			break
+		}
+		j.line(j.partial[:i])
+		j.partial = j.partial[i+1:]
+	}
+	return len(b), nil
+}
+
+// Close logs any final line left without a newline.
+func (j *jsonPassthrough) Close() error {
+	j.Lock()
+	defer j.Unlock()
+	if len(j.partial) > 0 {
+		j.line(j.partial)
+		j.partial = nil
+	}
+	return nil
+}
+
+func (j *jsonPassthrough) line(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+	var obj map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&obj); err != nil || obj == nil {
+		passLine(j.l.I, string(line))
+		return
+	}
+	level := Info
+	if name, ok := obj["level"].(string); ok {
+		if lv, ok := passthroughLevels[strings.ToLower(name)]; ok {
+			level = lv
+		}
+		delete(obj, "level")
+	}
+	var text string
+	for _, k := range passthroughMessageKeys {
+		if m, ok := obj[k]; ok {
+			text = fmt.Sprint(m)
+			delete(obj, k)
+			break
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]Field, len(keys))
+	for i, k := range keys {
+		fields[i] = Field{k, obj[k]}
+	}
+	passLine(j.l.At(level), text, fields...)
+}
+
+// passLine logs a line from another program through lp, if it is within the
+// log level, without exiting for a Fatal line, as a fatal record from another
+// program is no reason for this one to exit.
+func passLine(lp LevelPrinter, text string, fields ...Field) {
+	if lp.p == nil {
+		return
+	}
+	p := *lp.p
+	p.ofLog = false
+	if c := p.enabled(); c != nil {
+		p.emit(c, text, "", fields...)
+	}
+}
+
+// LevelWriter is Log.LevelWriter for the standard Log that writes to
+// os.Stdout.
+func LevelWriter(level int) io.WriteCloser { return l.LevelWriter(level) }
+
+// LevelWriter returns a writer for the output of sub-processes, such as the
+// Stderr of an exec.Cmd, that logs each line written to it through the
+// printer of l for level, with the code location of the writer. Writes need
+// not end on a newline, as what follows the last one is held until the next
+// write or Close, which logs it. As for JSONPassthroughWriter, a Fatal line
+// does not exit.
+func (l *Log) LevelWriter(level int) io.WriteCloser {
+	return &linePassthrough{lp: l.At(level)}
+}
+
+type linePassthrough struct {
+	sync.Mutex
+	lp      LevelPrinter
+	partial []byte
+}
+
+func (w *linePassthrough) Write(b []byte) (n int, err error) {
+	w.Lock()
+	defer w.Unlock()
+	w.partial = append(w.partial, b...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	return len(b), nil
+}
+
+// Close logs any final line left without a newline.
+func (w *linePassthrough) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.partial) > 0 {
+		w.line(w.partial)
+		w.partial = nil
+	}
+	return nil
+}
+
+func (w *linePassthrough) line(line []byte) {
+	passLine(w.lp, string(bytes.TrimRight(line, "\r")))
+}
@@ -0,0 +1,72 @@
+package lol_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestJSONPassthroughWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	w := lol.JSONPassthroughWriter(l)
+	io.WriteString(w, `{"level":"warning","msg":"disk low","free":12,`)
+	io.WriteString(w, `"dev":"sda"}`+"\nplain text\n"+`{"msg":"tail"`+"}")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines before Close, want 2: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], lol.LevelSpecs[lol.Warn].Name) ||
+		!strings.Contains(lines[0], "disk low dev=sda free=12") {
+		t.Fatalf("json line not re-emitted as warning: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], lol.LevelSpecs[lol.Info].Name) ||
+		!strings.Contains(lines[1], "plain text") {
+		t.Fatalf("plain line not passed through at info: %q", lines[1])
+	}
+	w.Close()
+	if !strings.Contains(buf.String(), "tail") {
+		t.Fatalf("partial line not flushed on Close")
+	}
+}
+
+func TestLevelWriter(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	w := l.LevelWriter(lol.Warn)
+	io.WriteString(w, "compiling ")
+	io.WriteString(w, "main.go\r\nwarning: unused")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0],
+		lol.LevelSpecs[lol.Warn].Name+" compiling main.go ") {
+		t.Fatalf("unexpected lines before Close: %q", lines)
+	}
+	w.Close()
+	if !strings.Contains(buf.String(), " warning: unused ") {
+		t.Fatalf("partial line not written on Close: %q", buf.String())
+	}
+	buf.Reset()
+	io.WriteString(l.LevelWriter(lol.Debug), "hidden\n")
+	if buf.Len() != 0 {
+		t.Fatalf("debug line written at info: %q", buf.String())
+	}
+}
+
+func TestJSONPassthroughLevel(t *testing.T) {
+	lol.SetLogLevel(lol.Warn)
+	defer lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	w := lol.JSONPassthroughWriter(l)
+	io.WriteString(w, `{"level":"debug","msg":"child detail"}`+"\nplain\n")
+	io.WriteString(w, `{"level":"error","msg":"child failed"}`+"\n")
+	if strings.Contains(buf.String(), "child detail") ||
+		strings.Contains(buf.String(), "plain") ||
+		!strings.Contains(buf.String(), "child failed") {
+		t.Fatalf("lines not filtered by the log level: %q", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package lol
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	piiEmail = regexp.MustCompile(
+		`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	// the forms shortened with :: need two groups, so that code such as
+	// std::vector is not taken for an address
+	piiIP = regexp.MustCompile(
+		`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b|` +
+			`\b(?:[0-9A-Fa-f]{1,4}:){7}[0-9A-Fa-f]{1,4}\b|` +
+			`\b(?:[0-9A-Fa-f]{1,4}:){1,6}(?::[0-9A-Fa-f]{1,4}){1,6}\b|` +
+			`\b(?:[0-9A-Fa-f]{1,4}:){2,7}:|` +
+			`\B::(?:[0-9A-Fa-f]{1,4}:){1,6}[0-9A-Fa-f]{1,4}\b`)
+	piiPhone = regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?` +
+		`(?:\(\d{1,4}\)[\s.\-]?|\b\d{2,4}[\s.\-])\d{3,4}[\s.\-]?\d{3,4}\b`)
+)
+
+// SetPIIScrubber sets a function that every encoder passes the message text
+// and the string field values of each entry through, however the entry was
+// made, to take personal data out of free text. ScrubPII is one that covers
+// the common cases. Passing nil, the default, scrubs nothing.
+func SetPIIScrubber(fn func(string) string) {
+	update(func(c *config) { c.piiScrubber = fn })
+}
+
+// ScrubPII replaces email addresses with [email], IPv4 and IPv6 addresses with
+// [ip] and phone numbers with [phone]. IPv6 addresses shortened with :: are
+// only recognised with at least two groups and a decimal digit. Phone numbers
+// are recognised by their shape, digit groups with separators and an optional
+// country code, so other numbers written the same way are replaced too.
+func ScrubPII(s string) string {
+	s = piiEmail.ReplaceAllLiteralString(s, "[email]")
+	s = piiIP.ReplaceAllStringFunc(s, func(ip string) string {
+		if !strings.ContainsAny(ip, "0123456789") {
+			return ip
+		}
+		return "[ip]"
+	})
+	return piiPhone.ReplaceAllLiteralString(s, "[phone]")
+}
+
+// scrubText is text passed through the PII scrubber, if one is set.
+func (c *config) scrubText(text string) string {
+	if fn := c.piiScrubber; fn != nil {
+		return fn(text)
+	}
+	return text
+}
+
+// scrubFields returns fields with their string values passed through fn,
+// leaving fields itself untouched.
+func scrubFields(fn func(string) string, fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = Field{f.Key, scrubValue(fn, f.Value)}
+	}
+	return out
+}
+
+func scrubValue(fn func(string) string, v interface{}) interface{} {
+	switch nested := v.(type) {
+	case string:
+		return fn(nested)
+	case error:
+		return fn(nested.Error())
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(nested))
+		for k, nv := range nested {
+			out[k] = scrubValue(fn, nv)
+		}
+		return out
+	case []Field:
+		return scrubFields(fn, nested)
+	}
+	return v
+}
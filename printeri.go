@@ -0,0 +1,103 @@
+package lol
+
+import (
+	"fmt"
+)
+
+// PrinterI is the core of a LevelPrinter as an interface, for code that takes
+// a printer and is tested with a mock of it that records what it printed.
+// LevelPrinter.Printer returns one that prints with a LevelPrinter, and
+// GetNullPrinter one that prints nothing.
+type PrinterI interface {
+	Ln(a ...interface{})
+	F(format string, a ...interface{})
+	S(a ...interface{})
+	C(closure func() string)
+	CF(closure func() (format string, a []interface{}))
+	CS(closure func() interface{})
+	Chk(e error) bool
+	Err(format string, a ...interface{}) error
+	Errw(wrapped error, format string, a ...interface{}) error
+	With(kvs ...interface{}) PrinterI
+	Sample(n int) PrinterI
+}
+
+// Printer returns lp as a PrinterI. As the printing primitives of a
+// LevelPrinter are fields rather than methods, LevelPrinter cannot implement
+// PrinterI itself. Code locations are those of the callers of the PrinterI,
+// as they are for lp.
+func (lp LevelPrinter) Printer() PrinterI {
+	if lp.p == nil {
+		return GetNullPrinter()
+	}
+	p := *lp.p
+	// the methods of levelPrinterI are a frame between the caller and lp
+	p.skip++
+	return levelPrinterI{p.levelPrinter()}
+}
+
+type levelPrinterI struct{ lp LevelPrinter }
+
+func (l levelPrinterI) Ln(a ...interface{})               { l.lp.Ln(a...) }
+func (l levelPrinterI) F(format string, a ...interface{}) { l.lp.F(format, a...) }
+func (l levelPrinterI) S(a ...interface{})                { l.lp.S(a...) }
+func (l levelPrinterI) C(closure func() string)           { l.lp.C(closure) }
+func (l levelPrinterI) Chk(e error) bool                  { return l.lp.Chk(e) }
+
+func (l levelPrinterI) CF(closure func() (string, []interface{})) {
+	l.lp.CF(closure)
+}
+
+func (l levelPrinterI) CS(closure func() interface{}) { l.lp.CS(closure) }
+
+func (l levelPrinterI) Err(format string, a ...interface{}) error {
+	return l.lp.Err(format, a...)
+}
+
+func (l levelPrinterI) Errw(wrapped error, format string,
+	a ...interface{}) error {
+
+	return l.lp.Errw(wrapped, format, a...)
+}
+
+func (l levelPrinterI) With(kvs ...interface{}) PrinterI {
+	return levelPrinterI{l.lp.With(kvs...)}
+}
+
+func (l levelPrinterI) Sample(n int) PrinterI {
+	return levelPrinterI{l.lp.Sample(n)}
+}
+
+// GetNullPrinter returns a PrinterI that prints nothing, for code that wants a
+// printer where nothing should be logged. The closures of C, CF and CS are
+// never called. Chk still reports whether there is an error, Err and Errw
+// still return one, and With and Sample return the null printer itself.
+func GetNullPrinter() PrinterI {
+	return nullPrinter{}
+}
+
+type nullPrinter struct{}
+
+func (nullPrinter) Ln(a ...interface{})               {}
+func (nullPrinter) F(format string, a ...interface{}) {}
+func (nullPrinter) S(a ...interface{})                {}
+func (nullPrinter) C(closure func() string)           {}
+func (nullPrinter) Chk(e error) bool                  { return e != nil }
+
+func (nullPrinter) CF(closure func() (string, []interface{})) {}
+func (nullPrinter) CS(closure func() interface{})             {}
+
+func (nullPrinter) Err(format string, a ...interface{}) error {
+	return fmt.Errorf(format, a...)
+}
+
+func (nullPrinter) Errw(wrapped error, format string, a ...interface{}) error {
+	if wrapped == nil {
+		return fmt.Errorf(format, a...)
+	}
+	return fmt.Errorf(format+": %w", append(a[:len(a):len(a)], wrapped)...)
+}
+
+func (n nullPrinter) With(kvs ...interface{}) PrinterI { return n }
+
+func (n nullPrinter) Sample(int) PrinterI { return n }
@@ -0,0 +1,88 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+// recordingPrinter is the mock of a PrinterI that tests of code taking one
+// would use.
+type recordingPrinter struct{ lines []string }
+
+func (r *recordingPrinter) Ln(a ...interface{}) {
+	r.lines = append(r.lines, lol.JoinStrings(a...))
+}
+func (r *recordingPrinter) F(format string, a ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprintf(format, a...))
+}
+func (r *recordingPrinter) S(a ...interface{})      { r.Ln(a...) }
+func (r *recordingPrinter) C(closure func() string) { r.Ln(closure()) }
+func (r *recordingPrinter) Chk(e error) bool {
+	if e != nil {
+		r.Ln(e)
+	}
+	return e != nil
+}
+func (r *recordingPrinter) Err(format string, a ...interface{}) error {
+	r.F(format, a...)
+	return fmt.Errorf(format, a...)
+}
+
+// connect is code under test that takes a printer.
+func connect(p lol.PrinterI, addr string) error {
+	p.F("connecting to %s", addr)
+	return p.Err("refused by %s", addr)
+}
+
+func TestPrinterI(t *testing.T) {
+	mock := &recordingPrinter{}
+	if err := connect(mock, "db:5432"); err == nil ||
+		strings.Join(mock.lines, "|") !=
+			"connecting to db:5432|refused by db:5432" {
+		t.Fatalf("unexpected lines %q", mock.lines)
+	}
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	if connect(l.I.Printer(), "db:5432") == nil {
+		t.Fatalf("no error returned")
+	}
+	if strings.Count(buf.String(), "printeri_test.go:39") != 1 ||
+		strings.Count(buf.String(), "printeri_test.go:40") != 1 {
+		t.Fatalf("locations are not of the callers: %q", buf.String())
+	}
+	null := lol.GetNullPrinter()
+	if !null.Chk(errors.New("x")) || null.Chk(nil) || connect(null, "a") == nil {
+		t.Fatalf("null printer does not report errors")
+	}
+	if e := null.Errw(io.EOF, "read %s", "a"); !errors.Is(e, io.EOF) ||
+		e.Error() != "read a: EOF" {
+		t.Fatalf("null printer does not wrap: %v", e)
+	}
+	if null.With("k", "v") != null || null.Sample(2) != null {
+		t.Fatalf("With on the null printer is not the null printer")
+	}
+}
+
+func (r *recordingPrinter) Errw(wrapped error, format string,
+	a ...interface{}) error {
+
+	r.F(format+": %v", append(a, wrapped)...)
+	return fmt.Errorf(format+": %w", append(a, wrapped)...)
+}
+
+func (r *recordingPrinter) With(kvs ...interface{}) lol.PrinterI { return r }
+
+func (r *recordingPrinter) Sample(n int) lol.PrinterI { return r }
+
+func (r *recordingPrinter) CF(closure func() (string, []interface{})) {
+	format, a := closure()
+	r.F(format, a...)
+}
+
+func (r *recordingPrinter) CS(closure func() interface{}) { r.Ln(closure()) }
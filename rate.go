@@ -0,0 +1,58 @@
+package lol
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// counters holds the *counter for each name given to Count.
+var counters sync.Map
+
+type counter struct {
+	n atomic.Int64
+	// mx guards the count and time of the last Rate, from which the next one
+	// is worked out.
+	mx    sync.Mutex
+	lastN int64
+	lastT time.Time
+}
+
+func getCounter(name string) *counter {
+	if c, ok := counters.Load(name); ok {
+		return c.(*counter)
+	}
+	c, _ := counters.LoadOrStore(name, &counter{})
+	return c.(*counter)
+}
+
+// Count adds delta to the counter called name, which Rate reports on. It is
+// safe to call from any goroutine.
+func Count(name string, delta int64) {
+	getCounter(name).n.Add(delta)
+}
+
+// rateText renders the counter called name as name=total, followed by its
+// average rate per second since the previous call for the same name, which the
+// first call has none of.
+func rateText(name string) string {
+	c := getCounter(name)
+	now, n := time.Now(), c.n.Load()
+	c.mx.Lock()
+	lastN, lastT := c.lastN, c.lastT
+	c.lastN, c.lastT = n, now
+	c.mx.Unlock()
+	s := name + "=" + strconv.FormatInt(n, 10)
+	if lastT.IsZero() {
+		return s
+	}
+	elapsed := now.Sub(lastT).Seconds()
+	if elapsed <= 0 {
+		return s
+	}
+	r := math.Round(float64(n-lastN)/elapsed*10) / 10
+	return s + " (" + strconv.FormatFloat(r, 'f', -1, 64) + "/s)"
+}
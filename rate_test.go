@@ -0,0 +1,38 @@
+package lol_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestRate(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lol.Count("rate_test", 1)
+		}()
+	}
+	wg.Wait()
+	l.I.Rate("rate_test")
+	if !strings.Contains(buf.String(), " rate_test=10 ") {
+		t.Fatalf("first rate should be just the count: %q", buf.String())
+	}
+	lol.Count("rate_test", 5)
+	time.Sleep(10 * time.Millisecond)
+	buf.Reset()
+	l.I.Rate("rate_test")
+	if !regexp.MustCompile(`rate_test=15 \([0-9.]+/s\)`).
+		MatchString(buf.String()) {
+		t.Fatalf("rate missing: %q", buf.String())
+	}
+}
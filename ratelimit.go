@@ -0,0 +1,61 @@
+package lol
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// rateWindows holds the *rateWindow for each code location that has written a
+// line while SetRateLimit is on.
+var rateWindows sync.Map
+
+// rateWindow counts the lines of one code location in the current second.
+type rateWindow struct {
+	mx    sync.Mutex
+	start time.Time
+	n     int
+	// suppressed is how many lines were dropped since the last one written.
+	suppressed int
+}
+
+// SetRateLimit limits each code location to perSecond lines in every second,
+// so that an error in a tight loop cannot flood the log. Lines over the limit
+// are dropped, and the next line written from the same place ends with
+// "(suppressed N)" for the N dropped before it. Chk, Err and the rest count
+// towards the limit like Ln and F, except for the Fatal printer of a Log.
+// A perSecond of zero or less turns the limit off, which is the default.
+func SetRateLimit(perSecond int) {
+	update(func(c *config) { c.rateLimit = max(0, perSecond) })
+}
+
+// rateLimited counts e against the rate limit of its code location, reporting
+// whether it is over the limit and must be dropped. When it is not, a count
+// of lines dropped before it is added to its text.
+func (c *config) rateLimited(e *Entry) bool {
+	if c.rateLimit == 0 {
+		return false
+	}
+	v, ok := rateWindows.Load(e.CodeLocation)
+	if !ok {
+		v, _ = rateWindows.LoadOrStore(e.CodeLocation, &rateWindow{})
+	}
+	w := v.(*rateWindow)
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if e.Time.Sub(w.start) >= time.Second || e.Time.Before(w.start) {
+		w.start, w.n = e.Time, 0
+	}
+	if w.n >= c.rateLimit {
+		w.suppressed++
+		c.debugf("line at %s over the rate limit of %d per second",
+			e.CodeLocation, c.rateLimit)
+		return true
+	}
+	w.n++
+	if w.suppressed > 0 {
+		e.Text = fmt.Sprintf("%s (suppressed %d)", e.Text, w.suppressed)
+		w.suppressed = 0
+	}
+	return false
+}
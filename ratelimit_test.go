@@ -0,0 +1,46 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	lol.SetClock(func() time.Time { return now })
+	lol.SetRateLimit(2)
+	defer func() {
+		lol.SetRateLimit(0)
+		lol.SetClock(nil)
+	}()
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	storm := func(n int, other bool) {
+		for i := 0; i < n; i++ {
+			l.E.Chk(errors.New("storm"))
+			if other {
+				l.I.Ln("other site")
+			}
+		}
+	}
+	storm(5, true)
+	if n := strings.Count(buf.String(), " storm "); n != 2 {
+		t.Fatalf("%d lines of the storm in the first second: %q", n,
+			buf.String())
+	}
+	if n := strings.Count(buf.String(), " other site "); n != 2 {
+		t.Fatalf("call sites are not limited apart: %q", buf.String())
+	}
+	buf.Reset()
+	now = now.Add(time.Second)
+	storm(3, false)
+	if !strings.Contains(buf.String(), " storm (suppressed 3) ") ||
+		strings.Count(buf.String(), " storm") != 2 {
+		t.Fatalf("unexpected lines in the next second: %q", buf.String())
+	}
+}
@@ -0,0 +1,93 @@
+package lol
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// SetPanicFormatter sets the function Recover and RecoverAndRepanic use to
+// render a recovered value as the log message. Passing nil restores
+// FormatPanic.
+func SetPanicFormatter(fn func(any) string) {
+	update(func(c *config) { c.panicFormatter = fn })
+}
+
+// FormatPanic is the default panic formatter. Errors render as their message,
+// strings as themselves, and anything else as a spew dump.
+func FormatPanic(v any) string {
+	switch p := v.(type) {
+	case error:
+		return p.Error()
+	case string:
+		return p
+	}
+	return strings.TrimSpace(spew.Sdump(v))
+}
+
+// SetRecoverRepanic makes Recover panic again with the value it recovered once
+// it has logged it, as RecoverAndRepanic does, so whether a program carries on
+// after a panic can be chosen in one place. Recover through the Fatal printer
+// of a Log leaves the choice to the exit function from SetExitFunc instead,
+// which it calls once the panic is logged.
+func SetRecoverRepanic(repanic bool) {
+	update(func(c *config) { c.recoverRepanic = repanic })
+}
+
+// Recover is deferred to log a panic in flight through lp, with the stack
+// beneath it and the location of the panic, and then let the function return
+// normally, unless SetRecoverRepanic is on. It does nothing when there is no
+// panic.
+//
+//	defer lol.Recover(log.F)
+func Recover(lp LevelPrinter) {
+	if r := recover(); r != nil {
+		logPanic(lp, r)
+		if loadConfig().recoverRepanic {
+			panic(r)
+		}
+	}
+}
+
+// RecoverAndRepanic is Recover, except that after logging it panics again with
+// the same value so the panic carries on up the stack.
+func RecoverAndRepanic(lp LevelPrinter) {
+	if r := recover(); r != nil {
+		logPanic(lp, r)
+		panic(r)
+	}
+}
+
+func logPanic(lp LevelPrinter, r any) {
+	if lp.p == nil {
+		return
+	}
+	c := loadConfig()
+	format := c.panicFormatter
+	if format == nil {
+		format = FormatPanic
+	}
+	lp.p.emitAt(c, panicSite(c), "panic: "+format(r), stack(2))
+}
+
+// panicSite finds the code location that panicked, which is the first frame
+// outside the runtime beneath runtime.gopanic.
+func panicSite(c *config) string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	var panicking bool
+	for {
+		f, more := frames.Next()
+		if panicking && !strings.HasPrefix(f.Function, "runtime.") {
+			return locationAt(c, f.PC, f.File, f.Line)
+		}
+		if f.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			break
+		}
+	}
+	return location(c, 3)
+}
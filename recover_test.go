@@ -0,0 +1,90 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func panics(l *lol.Log, v any) {
+	defer lol.Recover(l.E)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	panics(l, errors.New("boom"))
+	out := buf.String()
+	if !strings.Contains(out, "panic: boom") ||
+		!strings.Contains(out, "recover_test.go:15") {
+		t.Fatalf("panic not logged at its site:\n%s", out)
+	}
+	buf.Reset()
+	lol.SetPanicFormatter(func(v any) string { return fmt.Sprintf("<%v>", v) })
+	defer lol.SetPanicFormatter(nil)
+	panics(l, 42)
+	if !strings.Contains(buf.String(), "panic: <42>") {
+		t.Fatalf("panic formatter not used:\n%s", buf.String())
+	}
+	lol.Recover(l.E)
+}
+
+func TestRecoverLocFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetLocFormat(lol.LocShort)
+	defer lol.SetLocFormat(lol.LocFull)
+	panics(l, "short")
+	if !strings.Contains(buf.String(), " recover_test.go:15") {
+		t.Fatalf("panic site not in the short format:\n%s", buf.String())
+	}
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	defer func() {
+		if r := recover(); r != "again" {
+			t.Fatalf("panic value %v not passed on", r)
+		}
+		if !strings.Contains(buf.String(), "panic: again") {
+			t.Fatalf("panic not logged before repanic")
+		}
+	}()
+	defer lol.RecoverAndRepanic(l.E)
+	panic("again")
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetRecoverRepanic(true)
+	defer lol.SetRecoverRepanic(false)
+	func() {
+		defer func() {
+			if r := recover(); r != "crash" {
+				t.Fatalf("panic value %v not passed on", r)
+			}
+		}()
+		panics(l, "crash")
+	}()
+	if !strings.Contains(buf.String(), "panic: crash") {
+		t.Fatalf("panic not logged before repanic:\n%s", buf.String())
+	}
+	n := len(exitCodes)
+	buf.Reset()
+	lol.SetRecoverRepanic(false)
+	func() {
+		defer lol.Recover(l.F)
+		panic("fatal")
+	}()
+	if !strings.Contains(buf.String(), "FTL panic: fatal") ||
+		len(exitCodes) != n+1 {
+		t.Fatalf("fatal panic did not exit: %q", buf.String())
+	}
+}
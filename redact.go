@@ -0,0 +1,184 @@
+package lol
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unsafe"
+)
+
+// SetRedactFields sets the names of struct fields, such as Password or APIKey,
+// whose values S and S1 print as Masked, matched case insensitively, as are
+// the string keys of maps. Values are redacted wherever they are nested within
+// structs, maps, slices, arrays, pointers and interfaces. The values passed to
+// S are never changed: what is printed is a copy. Calling it with no names
+// redacts nothing, and S prints its values as they are.
+func SetRedactFields(names ...string) {
+	m := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		m[strings.ToLower(n)] = struct{}{}
+	}
+	if len(names) == 0 {
+		m = nil
+	}
+	update(func(c *config) { c.redactFields = m })
+}
+
+// SetRedactPatterns sets regular expressions whose matches in the message of
+// every line are replaced with Masked, such as bearer tokens, email addresses
+// or runs of digits like card numbers that the arguments of F and Ln put
+// there.
+// Messages are redacted once they are formatted, before hooks see them and
+// before they are written, so the text is the same everywhere it goes, and
+// before SetMaxMessageLen shortens them. Calling it with no patterns redacts
+// nothing.
+func SetRedactPatterns(res ...*regexp.Regexp) {
+	var patterns []*regexp.Regexp
+	for _, re := range res {
+		if re != nil {
+			patterns = append(patterns, re)
+		}
+	}
+	update(func(c *config) { c.redactPatterns = patterns })
+}
+
+// redactText is text with the matches of the patterns from SetRedactPatterns
+// replaced.
+func (c *config) redactText(text string) string {
+	for _, re := range c.redactPatterns {
+		text = re.ReplaceAllLiteralString(text, Masked)
+	}
+	return text
+}
+
+// redactArgs returns a with the fields named to SetRedactFields redacted, or a
+// itself when there are none.
+func (c *config) redactArgs(a []interface{}) []interface{} {
+	if len(c.redactFields) == 0 {
+		return a
+	}
+	r := redactor{names: c.redactFields, seen: make(map[redactSeen]reflect.Value)}
+	out := make([]interface{}, len(a))
+	for i, v := range a {
+		if v == nil {
+			continue
+		}
+		out[i] = r.value(reflect.ValueOf(v), 0).Interface()
+	}
+	return out
+}
+
+// redactor copies values, redacting the fields in names along the way.
+type redactor struct {
+	names map[string]struct{}
+	// seen holds the copy made of each pointer followed, so that shared and
+	// cyclic pointers stay that way in the copy.
+	seen map[redactSeen]reflect.Value
+}
+
+type redactSeen struct {
+	t reflect.Type
+	p uintptr
+}
+
+func (r *redactor) redacts(name string) bool {
+	_, ok := r.names[strings.ToLower(name)]
+	return ok
+}
+
+// value returns a copy of v with the fields in names redacted, depth levels
+// down from the value passed to S.
+func (r *redactor) value(v reflect.Value, depth int) reflect.Value {
+	if depth > maxDiffDepth {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := redactSeen{v.Type(), v.Pointer()}
+		if cp, ok := r.seen[key]; ok {
+			return cp
+		}
+		cp := reflect.New(v.Type().Elem())
+		r.seen[key] = cp
+		cp.Elem().Set(r.value(v.Elem(), depth+1))
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(r.value(v.Elem(), depth+1))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < cp.NumField(); i++ {
+			f := cp.Field(i)
+			if !f.CanSet() {
+				// unexported fields are copied as well, through their
+				// address in the copy
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).
+					Elem()
+			}
+			if r.redacts(v.Type().Field(i).Name) {
+				f.Set(redacted(f.Type()))
+			} else {
+				f.Set(r.value(f, depth+1))
+			}
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k := it.Key()
+			if k.Kind() == reflect.String && r.redacts(k.String()) {
+				cp.SetMapIndex(k, redacted(v.Type().Elem()))
+			} else {
+				cp.SetMapIndex(k, r.value(it.Value(), depth+1))
+			}
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(r.value(v.Index(i), depth+1))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(r.value(v.Index(i), depth+1))
+		}
+		return cp
+	}
+	return v
+}
+
+// redacted is the value of type t printed for a redacted field: Masked for
+// strings, byte slices, interfaces and pointers to strings, and the zero value
+// for anything else.
+func redacted(t reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch {
+	case t.Kind() == reflect.String:
+		v.SetString(Masked)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes([]byte(Masked))
+	case t.Kind() == reflect.Interface && reflect.TypeOf(Masked).Implements(t):
+		v.Set(reflect.ValueOf(Masked))
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.String:
+		s := reflect.New(t.Elem())
+		s.Elem().SetString(Masked)
+		v.Set(s)
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package lol
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+
+	"go.uber.org/atomic"
+)
+
+// writeGuard counts the lines of one Log being written, and is shared by its
+// printers and those made from them, so that the stack is only looked at for a
+// write within a write while a line of the same Log is being written, rather
+// than while any line is.
+type writeGuard struct {
+	writing atomic.Int32
+}
+
+// anyGuard is the guard of printers made without one of their own.
+var anyGuard writeGuard
+
+// writeGuard is the guard of the Log of p.
+func (p *printer) writeGuard() *writeGuard {
+	if p.guard != nil {
+		return p.guard
+	}
+	return &anyGuard
+}
+
+// writeFunc and asyncRunFunc are the full names of printer.write and
+// asyncWriter.run, as they show in the stack.
+var writeFunc, asyncRunFunc string
+
+func init() {
+	writeFunc = runtime.FuncForPC(
+		reflect.ValueOf((*printer).write).Pointer()).Name()
+	asyncRunFunc = runtime.FuncForPC(
+		reflect.ValueOf((*asyncWriter).run).Pointer()).Name()
+}
+
+// inWrite is whether the caller of its caller was called from within
+// printer.write, as for a line logged by the writer of another line while it
+// is writing it.
+func inWrite() bool { return onStack(writeFunc) }
+
+// onStack is whether the caller of the caller of its caller was called from
+// within the function named fn.
+func onStack(fn string) bool {
+	var pcs [32]uintptr
+	for skip := 4; ; skip += len(pcs) {
+		n := runtime.Callers(skip, pcs[:])
+		frames := runtime.CallersFrames(pcs[:n])
+		for {
+			f, more := frames.Next()
+			if f.Function == fn {
+				return true
+			}
+			if !more {
+				break
+			}
+		}
+		if n < len(pcs) {
+			return false
+		}
+	}
+}
+
+// writeReentrant writes a line logged by a writer while it was writing
+// another to os.Stderr, as writing it to the writer could wait forever on a
+// lock the writer holds, or log again without end.
+func (p *printer) writeReentrant(c *config, e *Entry) {
+	if _, err := os.Stderr.Write(p.encoderFor(c)(e)); err != nil {
+		c.writeError(err)
+	}
+}
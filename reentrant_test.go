@@ -0,0 +1,113 @@
+package lol_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+// selfLoggingWriter logs through its own Log from within each write, as a
+// sink with diagnostics of its own might.
+type selfLoggingWriter struct {
+	l   *lol.Log
+	buf bytes.Buffer
+}
+
+func (s *selfLoggingWriter) Write(b []byte) (int, error) {
+	s.l.I.Ln("sink wrote", len(b), "bytes")
+	return s.buf.Write(b)
+}
+
+func TestReentrantWrite(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+	sink := &selfLoggingWriter{}
+	locked := &selfLoggingWriter{}
+	sink.l, _ = lol.New(sink)
+	locked.l, _ = lol.New(lol.SyncWriter(locked))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sink.l.I.Ln("request served")
+		locked.l.I.Ln("request served")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("logging from within a write did not return")
+	}
+	for _, s := range []*selfLoggingWriter{sink, locked} {
+		if !strings.Contains(s.buf.String(), " request served ") ||
+			strings.Contains(s.buf.String(), "sink wrote") {
+			t.Fatalf("unexpected lines in the sink %q", s.buf.String())
+		}
+	}
+	b, _ := os.ReadFile(f.Name())
+	if strings.Count(string(b), "sink wrote") != 2 {
+		t.Fatalf("lines from within writes not on stderr: %q", b)
+	}
+}
+
+// queueFillingWriter waits for ready before its first write, and then logs
+// through its own Log from within each write of a request line.
+type queueFillingWriter struct {
+	l     *lol.Log
+	ready chan struct{}
+	once  sync.Once
+	buf   lockedBuffer
+}
+
+func (q *queueFillingWriter) Write(b []byte) (int, error) {
+	q.once.Do(func() { <-q.ready })
+	if bytes.Contains(b, []byte("request")) {
+		q.l.I.Ln("sink wrote", len(b), "bytes")
+	}
+	return q.buf.Write(b)
+}
+
+func TestReentrantAsyncWrite(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+	sink := &queueFillingWriter{ready: make(chan struct{})}
+	w, flush := lol.NewAsyncWriter(sink, 1)
+	sink.l, _ = lol.New(w)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// the first line waits in the sink and the second fills the queue
+		sink.l.I.Ln("request one")
+		sink.l.I.Ln("request two")
+		close(sink.ready)
+		flush()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("logging from within an asynchronous write did not return")
+	}
+	w.Close()
+	if strings.Count(sink.buf.String(), " request ") != 2 {
+		t.Fatalf("request lines missing from the sink %q", sink.buf.String())
+	}
+	b, _ := os.ReadFile(f.Name())
+	if !strings.Contains(string(b), "sink wrote") {
+		t.Fatalf("lines from within writes not on stderr: %q", b)
+	}
+}
@@ -0,0 +1,47 @@
+package lol
+
+import (
+	"fmt"
+	"time"
+)
+
+// nullLevelPrinter returns a LevelPrinter that prints nothing, which Logs
+// built with the lol_release tag have for Debug and Trace. As for
+// GetNullPrinter, Chk and ChkE still report errors, and the printers that
+// return an error still return one. It keeps p, dropped, so that constructors
+// can still give it a writer and printers made from it print nothing either.
+func nullLevelPrinter(p printer) LevelPrinter {
+	p.dropped = true
+	return LevelPrinter{
+		Ln:  func(a ...interface{}) {},
+		F:   func(format string, a ...interface{}) {},
+		S:   func(a ...interface{}) {},
+		C:   func(closure func() string) {},
+		CF:  func(closure func() (string, []interface{})) {},
+		CS:  func(closure func() interface{}) {},
+		Chk: func(e error) bool { return e != nil },
+		ChkE: func(e error) error {
+			if e == nil {
+				return nil
+			}
+			return &locatedError{location(loadConfig(), 1), e}
+		},
+		Err: func(format string, a ...interface{}) error {
+			return fmt.Errorf(format, a...)
+		},
+		Errw: func(wrapped error, format string, a ...interface{}) error {
+			return GetNullPrinter().Errw(wrapped, format, a...)
+		},
+		Stack: func(a ...interface{}) {},
+		S1:    func(a ...interface{}) {},
+		Retry: func(attempt int, wait time.Duration, err error) {},
+		Raw1:  func(s string) {},
+		ErrCode: func(code int, format string, a ...interface{}) error {
+			return &CodedError{code, fmt.Errorf(format, a...)}
+		},
+		Rate:  func(name string) {},
+		KV:    func(msg string, kvs ...interface{}) {},
+		Table: func(headers []string, rows [][]string) {},
+		p:     &p,
+	}
+}
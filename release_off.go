@@ -0,0 +1,7 @@
+//go:build !lol_release
+
+package lol
+
+// releaseBuild is set by the lol_release build tag, and without it Logs print
+// at every level.
+const releaseBuild = false
@@ -0,0 +1,6 @@
+//go:build !lol_release
+
+package lol_test
+
+// releaseBuild is whether the tests are built with the lol_release tag.
+const releaseBuild = false
@@ -0,0 +1,9 @@
+//go:build lol_release
+
+package lol
+
+// releaseBuild is set by the lol_release build tag, with which the Debug and
+// Trace printers of every Log print nothing: the D and T printers of a Log and
+// the D and T of its Check are those of nullLevelPrinter, whatever the log
+// level. Printers from GetPrinter and LevelPrinter.As are not affected.
+const releaseBuild = true
@@ -0,0 +1,42 @@
+//go:build lol_release
+
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+// releaseBuild is whether the tests are built with the lol_release tag.
+const releaseBuild = true
+
+func TestRelease(t *testing.T) {
+	lol.SetLogLevel(lol.Trace)
+	defer lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, c := lol.New(&buf)
+	l.T.Ln("trace")
+	l.D.F("debug %d", 1)
+	if !c.D(errors.New("x")) || l.T.Err("e") == nil || l.D.Enabled() ||
+		buf.Len() != 0 {
+		t.Fatalf("debug and trace printers are not null: %q", buf.String())
+	}
+	l.I.Ln("info")
+	if buf.Len() == 0 {
+		t.Fatalf("info printer is null")
+	}
+	// constructors that give each printer a writer of its own
+	buf.Reset()
+	m, _ := lol.NewMulti(lol.LeveledOutput{Writer: &buf, Level: lol.Trace})
+	s, _ := lol.NewSplit(&buf, &buf, 0)
+	m.D.Ln("multi")
+	s.T.Ln("split")
+	w := lol.JSONPassthroughWriter(l)
+	_, _ = w.Write([]byte(`{"level":"debug","msg":"child"}` + "\n"))
+	if buf.Len() != 0 {
+		t.Fatalf("debug and trace lines written: %q", buf.String())
+	}
+}
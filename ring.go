@@ -0,0 +1,135 @@
+package lol
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// RingWriter keeps the most recent lines written to it in memory, such as to
+// put the last moments before an error into a bug report.
+type RingWriter struct {
+	mx    sync.Mutex
+	lines []string
+	// next is where the next line goes, and full is whether lines has wrapped
+	// around, making next the oldest line.
+	next int
+	full bool
+}
+
+// NewRingWriter returns a RingWriter holding up to capacity lines, dropping the
+// oldest line for each new one once it is full. It can be given to New like
+// any other writer, or to NewMulti alongside the main one, and wrapped in
+// SyncWriter or a BatchWriter of its own. It holds the lines of every level,
+// whatever the log level is: lines above the log level are written to it,
+// through its wrappers, and to no other writer, without running hooks or
+// counting in the metrics, so a RingWriter at Trace in NewMulti costs the
+// formatting of every Debug and Trace line. Enabled does not count those
+// lines. A RingWriter behind a wrapper that also writes elsewhere, such as a
+// BatchWriter of a NewMulti, only gets lines within the log level. Writes
+// never block for longer than it takes to copy the line.
+func NewRingWriter(capacity int) (*RingWriter, error) {
+	if capacity < 1 {
+		return nil, errors.New("lol: ring writer capacity must be at least 1")
+	}
+	return &RingWriter{lines: make([]string, capacity)}, nil
+}
+
+// Write adds each line of b, with the detail beneath an entry counting as
+// lines of its own.
+func (r *RingWriter) Write(b []byte) (n int, err error) {
+	s := strings.TrimSuffix(string(b), "\n")
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for _, line := range strings.Split(s, "\n") {
+		r.lines[r.next] = line
+		if r.next++; r.next == len(r.lines) {
+			r.next, r.full = 0, true
+		}
+	}
+	return len(b), nil
+}
+
+// holdsRing is whether lines above the log level have a RingWriter in w to
+// go to.
+func holdsRing(w io.Writer) bool { return ringRoutes(w, nil) }
+
+// ringRoutes calls write, unless it is nil, with the writers that lines above
+// the log level go to for the RingWriters in w, which is w itself when all it
+// writes to are RingWriters, so that a wrapper such as a BatchWriter keeps
+// them in order with the lines before them, or else each output of NewMulti
+// that is one, reporting whether there were any.
+func ringRoutes(w io.Writer, write func(io.Writer)) (found bool) {
+	if onlyRings(w) {
+		if write != nil {
+			write(w)
+		}
+		return true
+	}
+	if m, ok := w.(*multiWriter); ok {
+		for _, w := range m.writers {
+			if ringRoutes(w, write) {
+				found = true
+			}
+		}
+	}
+	return
+}
+
+// onlyRings is whether w is a RingWriter or a wrapper of only RingWriters.
+func onlyRings(w io.Writer) bool {
+	switch w := w.(type) {
+	case *RingWriter:
+		return true
+	case wrapper:
+		ws := w.wrapped()
+		for _, w := range ws {
+			if !onlyRings(w) {
+				return false
+			}
+		}
+		return len(ws) > 0
+	}
+	return false
+}
+
+// forRings returns a copy of c for a line above the log level, which goes
+// only to RingWriters.
+func (c *config) forRings() *config {
+	rc := *c
+	rc.ringsOnly = true
+	return &rc
+}
+
+// writeRings writes e to the RingWriters of the writer of p, through the
+// writers from ringRoutes.
+func (p *printer) writeRings(c *config, e *Entry) {
+	b := p.encoderFor(c)(e)
+	ringRoutes(p.writer, func(w io.Writer) {
+		if _, err := w.Write(b); err != nil {
+			c.writeError(err)
+		}
+	})
+}
+
+// Lines returns the lines held, oldest first, without their newlines.
+func (r *RingWriter) Lines() []string {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...),
+		r.lines[:r.next]...)
+}
+
+// Dump writes the lines held to w, oldest first, one per line.
+func (r *RingWriter) Dump(w io.Writer) (err error) {
+	for _, line := range r.Lines() {
+		if _, err = io.WriteString(w, line+"\n"); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package lol_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestRingWriter(t *testing.T) {
+	if _, err := lol.NewRingWriter(0); err == nil {
+		t.Fatalf("no error for an empty ring")
+	}
+	r, err := lol.NewRingWriter(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := lol.New(r)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.I.Ln("concurrent")
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 3; i++ {
+		l.I.Ln(fmt.Sprint("line ", i))
+	}
+	lines := r.Lines()
+	if len(lines) != 3 || !strings.Contains(lines[0], " line 0 ") ||
+		!strings.Contains(lines[2], " line 2 ") {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	var buf bytes.Buffer
+	if err = r.Dump(&buf); err != nil ||
+		buf.String() != strings.Join(lines, "\n")+"\n" {
+		t.Fatalf("unexpected dump %q: %v", buf.String(), err)
+	}
+}
+
+func TestRingWriterBelowLevel(t *testing.T) {
+	skipInRelease(t)
+	lol.SetLogLevel(lol.Info)
+	r, _ := lol.NewRingWriter(10)
+	var buf bytes.Buffer
+	var hooked int
+	defer lol.RemoveHooks()
+	lol.AddHook(func(level int, loc, msg string) { hooked++ })
+	l, _ := lol.NewMulti(
+		lol.LeveledOutput{Writer: &buf, Level: lol.Trace},
+		lol.LeveledOutput{Writer: r, Level: lol.Debug},
+	)
+	l.I.Ln("kept")
+	l.D.Ln("detail")
+	l.T.Ln("noise")
+	lines := r.Lines()
+	if len(lines) != 2 || !strings.Contains(lines[1], " detail ") {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+	if strings.Contains(buf.String(), "detail") || hooked != 1 {
+		t.Fatalf("line above the log level went beyond the ring, %d hooks:"+
+			"\n%s", hooked, buf.String())
+	}
+}
+
+func TestRingWriterBatched(t *testing.T) {
+	skipInRelease(t)
+	lol.SetLogLevel(lol.Info)
+	r, _ := lol.NewRingWriter(10)
+	bw := lol.NewBatchWriter(r, 0, 0)
+	l, _ := lol.New(bw)
+	l.I.Ln("first")
+	l.D.Ln("second")
+	if l.D.Enabled() {
+		t.Fatalf("Enabled counts lines that only the ring takes")
+	}
+	if len(r.Lines()) != 0 {
+		t.Fatalf("line went around the BatchWriter: %q", r.Lines())
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := r.Lines()
+	if len(lines) != 2 || !strings.Contains(lines[0], " first ") ||
+		!strings.Contains(lines[1], " second ") {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
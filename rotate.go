@@ -0,0 +1,157 @@
+package lol
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingWriter is the writer returned by NewRotatingWriter.
+type rotatingWriter struct {
+	dir, prefix string
+	maxBytes    int64
+	gzipRolled  bool
+	mx          sync.Mutex
+	f           *os.File
+	size        int64
+	// compressing is waited on by Close for the files being gzipped.
+	compressing sync.WaitGroup
+	closed      bool
+}
+
+// NewRotatingWriter returns a writer for New that writes to files in dir,
+// created if it is missing, named for the time each was opened, as
+// prefix-20060102T150405.000000000.log, with the time from SetClock. A write
+// that would take the file past maxBytes goes to a new file instead, unless
+// the file is still empty, so that a line is never split across files. With
+// gzipRolled the files rolled over are compressed in the background into a
+// file of the same name ending in .gz, which only appears under that name
+// once it is complete, and then the uncompressed one is removed. Close waits
+// for the compression to finish. Errors from compression go to the handler
+// from SetWriteErrorHandler.
+func NewRotatingWriter(dir, prefix string, maxBytes int64,
+	gzipRolled bool) (io.WriteCloser, error) {
+
+	if maxBytes < 1 {
+		return nil, errors.New("lol: rotating writer maxBytes must be at " +
+			"least 1")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingWriter{dir: dir, prefix: prefix, maxBytes: maxBytes,
+		gzipRolled: gzipRolled}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingWriter) Write(b []byte) (n int, err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.closed {
+		return 0, ErrWriterClosed
+	}
+	if r.size > 0 && r.size+int64(len(b)) > r.maxBytes {
+		if err = r.roll(); err != nil {
+			return
+		}
+	}
+	n, err = r.f.Write(b)
+	r.size += int64(n)
+	return
+}
+
+// open starts a new file named for the current time, with a count after the
+// time if a file of that name is already there.
+func (r *rotatingWriter) open() (err error) {
+	stamp := loadConfig().now().Format("20060102T150405.000000000")
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s-%s.log", r.prefix, stamp)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%s-%d.log", r.prefix, stamp, i)
+		}
+		var f *os.File
+		f, err = os.OpenFile(filepath.Join(r.dir, name),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		r.f, r.size = f, 0
+		return
+	}
+}
+
+// roll closes the current file, compressing it if asked to, and opens the
+// next.
+func (r *rotatingWriter) roll() (err error) {
+	old := r.f
+	if err = old.Close(); err != nil {
+		return
+	}
+	if r.gzipRolled {
+		r.compressing.Add(1)
+		go func() {
+			defer r.compressing.Done()
+			if err := gzipFile(old.Name()); err != nil {
+				loadConfig().writeError(err)
+			}
+		}()
+	}
+	return r.open()
+}
+
+// gzipFile compresses the file at name into name.gz, through a temporary file
+// that is renamed once it is complete, and removes the original.
+func gzipFile(name string) (err error) {
+	var in *os.File
+	if in, err = os.Open(name); err != nil {
+		return
+	}
+	defer in.Close()
+	tmp := name + ".gz.tmp"
+	var out *os.File
+	if out, err = os.Create(tmp); err != nil {
+		return
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if e := zw.Close(); err == nil {
+		err = e
+	}
+	if e := out.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	if err = os.Rename(tmp, name+".gz"); err != nil {
+		return
+	}
+	return os.Remove(name)
+}
+
+// Close syncs and closes the current file and waits for the rolled files to
+// be compressed.
+func (r *rotatingWriter) Close() (err error) {
+	r.mx.Lock()
+	if !r.closed {
+		r.closed = true
+		err = r.f.Sync()
+		if e := r.f.Close(); err == nil {
+			err = e
+		}
+	}
+	r.mx.Unlock()
+	r.compressing.Wait()
+	return
+}
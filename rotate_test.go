@@ -0,0 +1,48 @@
+package lol_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := lol.NewRotatingWriter(dir, "app", 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := lol.New(w)
+	for i := 0; i < 5; i++ {
+		l.I.Ln("a line long enough that two do not fit in a file")
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	plain, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(gz) != 4 || len(plain) != 1 {
+		t.Fatalf("%d compressed and %d plain files", len(gz), len(plain))
+	}
+	f, err := os.Open(gz[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil || strings.Count(string(b), " a line long enough ") != 1 {
+		t.Fatalf("unexpected rolled file %q: %v", b, err)
+	}
+	if _, err = w.Write([]byte("late\n")); err != lol.ErrWriterClosed {
+		t.Fatalf("write after close returned %v", err)
+	}
+}
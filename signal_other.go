@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lol
+
+// InstallSignalHandlers does nothing on this platform, which has no SIGUSR1
+// or SIGUSR2.
+func InstallSignalHandlers() {}
@@ -0,0 +1,48 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var installSignals sync.Once
+
+// InstallSignalHandlers makes SIGUSR1 raise the log level one step towards
+// Trace and SIGUSR2 lower it one step towards Off, so the verbosity of a long
+// running program can be changed without restarting it. Each change is
+// reported with a line from the standard Log, which is written even when the
+// new level leaves out Info lines. Calling it again does nothing, and on
+// platforms without these signals, such as Windows, it does nothing at all.
+func InstallSignalHandlers() {
+	installSignals.Do(func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+		go func() {
+			for sig := range sigs {
+				step := 1
+				if sig == syscall.SIGUSR2 {
+					step = -1
+				}
+				stepLevel(step)
+			}
+		}()
+	})
+}
+
+// stepLevel moves the log level step levels more verbose, within Off and
+// Trace, reporting the new level when it changed.
+func stepLevel(step int) {
+	var from, to int32
+	update(func(c *config) {
+		from = c.level
+		c.level = max(Off, min(Trace, from+int32(step)))
+		to = c.level
+	})
+	if to != from {
+		l.I.p.emit(loadConfig(), "log level set to "+LevelNames[to], "")
+	}
+}
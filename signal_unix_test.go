@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestInstallSignalHandlers(t *testing.T) {
+	lol.SetLogLevel(lol.Debug)
+	defer lol.SetLogLevel(lol.Info)
+	lol.InstallSignalHandlers()
+	lol.InstallSignalHandlers()
+	waitFor := func(sig syscall.Signal, level int) {
+		if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; lol.GetLogLevel() != level; i++ {
+			if i == 100 {
+				t.Fatalf("level %d after %v, not %d", lol.GetLogLevel(), sig,
+					level)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitFor(syscall.SIGUSR1, lol.Trace)
+	waitFor(syscall.SIGUSR2, lol.Debug)
+}
@@ -0,0 +1,122 @@
+package lol
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+)
+
+// slogHandler is the slog.Handler returned by NewSlogHandler.
+type slogHandler struct {
+	p printer
+	// fields are from WithAttrs, with their keys already qualified by the
+	// groups they were added in.
+	fields []Field
+	// group is the prefix of the keys of attributes added from now on, with
+	// a dot after each group.
+	group string
+}
+
+// NewSlogHandler returns a slog.Handler that writes the records of a
+// slog.Logger to w as a Log would, so that code using log/slog goes through
+// the same levels, code locations and encoding as the rest of a program.
+// slog.LevelDebug, LevelInfo, LevelWarn and LevelError log at Debug, Info,
+// Warn and Error, levels between them at the next less severe one and levels
+// below LevelDebug at Trace. Records are filtered by the code location of
+// their PC as the lines of a Log are, by the levels from SetLogLevel and
+// SetFileLevel, SetLocFilter and the trace sampler. Attributes, including
+// those from WithAttrs, become the fields of the entry, with the names of
+// groups joined to their keys with dots.
+func NewSlogHandler(w io.Writer) slog.Handler {
+	p := printer{writer: w, guard: &writeGuard{}}
+	p.detectTerminal()
+	return &slogHandler{p: p}
+}
+
+// slogLevel is the level that records at l are logged at.
+func slogLevel(l slog.Level) int32 {
+	switch {
+	case l >= slog.LevelError:
+		return Error
+	case l >= slog.LevelWarn:
+		return Warn
+	case l >= slog.LevelInfo:
+		return Info
+	case l >= slog.LevelDebug:
+		return Debug
+	}
+	return Trace
+}
+
+// Enabled is whether records at l may be written. The code location that
+// SetFileLevel and SetLocFilter go by is only known in Handle, so while file
+// levels are set, or w holds a RingWriter, every level may be.
+func (h *slogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	c := loadConfig()
+	return !h.p.dropped && (slogLevel(l) <= c.level ||
+		len(c.fileLevels) > 0 || holdsRing(h.p.writer))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	p := h.p
+	p.level, p.ctx = slogLevel(r.Level), ctx
+	c := p.enabledAt(r.PC)
+	if c == nil {
+		return nil
+	}
+	var loc, function string
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		loc, function = locationAt(c, frame.PC, frame.File, frame.Line),
+			frame.Function
+	}
+	fields := append([]Field(nil), h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.group, a)
+		return true
+	})
+	p.emitEntry(c, loc, function, r.Message, "", fields)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.fields = append([]Field(nil), h.fields...)
+	for _, a := range attrs {
+		h2.fields = appendAttr(h2.fields, h.group, a)
+	}
+	return &h2
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group += name + "."
+	return &h2
+}
+
+// appendAttr appends a to fields with its key after the group prefix, as one
+// field for each attribute in it when it is a group, and as nothing when it is
+// empty, as slog.Handler requires.
+func appendAttr(fields []Field, group string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			fields = appendAttr(fields, group, g)
+		}
+		return fields
+	}
+	return append(fields, Field{group + a.Key, a.Value.Any()})
+}
@@ -0,0 +1,57 @@
+package lol_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestSlogHandler(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	defer lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	log := slog.New(lol.NewSlogHandler(&buf)).With("svc", "api").
+		WithGroup("req")
+	log.Warn("slow", "ms", 250, slog.Group("user", "id", 7))
+	line := buf.String()
+	if !strings.Contains(line, " WRN slow svc=api req.ms=250 req.user.id=7 ") ||
+		!strings.Contains(line, "slog_test.go:19") {
+		t.Fatalf("unexpected line %q", line)
+	}
+	buf.Reset()
+	log.Debug("hidden")
+	if buf.Len() != 0 || log.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatalf("debug record was written at Info: %q", buf.String())
+	}
+	lol.SetLogLevel(lol.Debug)
+	log.Debug("shown")
+	if !strings.Contains(buf.String(), " DBG shown svc=api ") {
+		t.Fatalf("debug record was not written at Debug: %q", buf.String())
+	}
+}
+
+func TestSlogHandlerFileLevel(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	log := slog.New(lol.NewSlogHandler(&buf))
+	lol.SetFileLevel("slog_test.go", lol.Warn)
+	log.Info("quiet")
+	if buf.Len() != 0 {
+		t.Fatalf("record written above the level of its file: %q",
+			buf.String())
+	}
+	lol.SetFileLevel("slog_test.go", lol.Debug)
+	defer lol.SetFileLevel("slog_test.go", -1)
+	if !log.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatalf("debug records not enabled with a file level of Debug")
+	}
+	log.Debug("loud")
+	if !strings.Contains(buf.String(), " DBG loud ") {
+		t.Fatalf("record not written at the level of its file: %q",
+			buf.String())
+	}
+}
@@ -0,0 +1,233 @@
+package lol
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// sqliteBatchSize is how many rows are held before they are committed
+	// without waiting for the next tick.
+	sqliteBatchSize = 512
+	// sqliteCommitInterval is how often held rows are committed.
+	sqliteCommitInterval = time.Second
+	// sqlitePruneInterval is how often rows past the retention are deleted.
+	sqlitePruneInterval = time.Hour
+)
+
+// sqliteDrivers are the database/sql driver names of SQLite drivers, the pure
+// Go modernc.org/sqlite first, then the cgo github.com/mattn/go-sqlite3.
+var sqliteDrivers = []string{"sqlite", "sqlite3"}
+
+// ErrNoSQLiteDriver is returned by NewSQLite when the program has not imported
+// a SQLite driver for database/sql.
+var ErrNoSQLiteDriver = errors.New("lol: no SQLite driver registered, " +
+	"import modernc.org/sqlite, or github.com/mattn/go-sqlite3 for cgo builds")
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS logs (
+	ts INTEGER NOT NULL,
+	level TEXT NOT NULL,
+	loc TEXT NOT NULL,
+	msg TEXT NOT NULL,
+	fields TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS logs_ts ON logs (ts)`
+
+// SetSQLiteRetention makes logs from NewSQLite delete rows older than days,
+// checked once an hour, so the database does not grow without end. Zero, the
+// default, keeps every row.
+func SetSQLiteRetention(days int) {
+	update(func(c *config) { c.sqliteRetention = days })
+}
+
+// NewSQLite returns a Log that inserts each entry into the logs table of the
+// SQLite database at path, creating both when missing, for logs that can be
+// queried with SQL. The table has the columns ts, in Unix nanoseconds, level,
+// loc, msg, with any detail beneath the text, and fields, as a JSON object.
+// Rows are inserted in batches, each in one transaction, committed every
+// second or once enough are waiting, and Fatal lines or SetFlushLevel commit
+// straight away. skip is as for NewUnixgram. The closer returned with it
+// commits the rows still waiting and closes the database, after which lines
+// fail with ErrWriterClosed.
+//
+// The program must import a database/sql driver for SQLite, such as the pure
+// Go modernc.org/sqlite, or ErrNoSQLiteDriver is returned.
+func NewSQLite(path string, skip int) (l *Log, c *Check, closer io.Closer,
+	err error) {
+
+	var w *sqliteWriter
+	if w, err = openSQLite(path); err != nil {
+		return
+	}
+	go w.run()
+	l, c = newLog(printer{writer: w, encoder: JSONEncoder, skip: skip})
+	return l, c, w, nil
+}
+
+func openSQLite(path string) (w *sqliteWriter, err error) {
+	var name string
+	for _, d := range sqliteDrivers {
+		if slices.Contains(sql.Drivers(), d) {
+			name = d
+			break
+		}
+	}
+	if name == "" {
+		return nil, ErrNoSQLiteDriver
+	}
+	var db *sql.DB
+	if db, err = sql.Open(name, path); err != nil {
+		return
+	}
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return
+	}
+	return &sqliteWriter{db: db, done: make(chan struct{}),
+		finished: make(chan struct{})}, nil
+}
+
+// sqliteRow is an entry waiting to be inserted.
+type sqliteRow struct {
+	ts                      int64
+	level, loc, msg, fields string
+}
+
+// sqliteWriter takes the lines of JSONEncoder and holds them as rows until
+// they are committed.
+type sqliteWriter struct {
+	db *sql.DB
+	mx sync.Mutex
+	// rows are held until the next commit.
+	rows []sqliteRow
+	// committing is held for the whole of a commit, so that Flush returns
+	// only once every row written before it is in the database.
+	committing sync.Mutex
+	pruned     time.Time
+	// done stops run, which closes finished once it has.
+	done, finished chan struct{}
+	closed         bool
+}
+
+func (s *sqliteWriter) Write(b []byte) (n int, err error) {
+	var r struct {
+		Time   time.Time       `json:"time"`
+		Level  string          `json:"level"`
+		Loc    string          `json:"loc"`
+		Msg    string          `json:"msg"`
+		Fields json.RawMessage `json:"fields"`
+		Detail string          `json:"detail"`
+	}
+	if err = json.Unmarshal(b, &r); err != nil {
+		return
+	}
+	if r.Detail != "" {
+		r.Msg += "\n" + r.Detail
+	}
+	s.mx.Lock()
+	if s.closed {
+		s.mx.Unlock()
+		return 0, ErrWriterClosed
+	}
+	s.rows = append(s.rows, sqliteRow{r.Time.UnixNano(), r.Level, r.Loc, r.Msg,
+		string(r.Fields)})
+	full := len(s.rows) >= sqliteBatchSize
+	s.mx.Unlock()
+	if full {
+		if err = s.Flush(); err != nil {
+			return
+		}
+	}
+	return len(b), nil
+}
+
+// Flush commits the rows that are waiting, in one transaction, and deletes
+// those past the retention when it is time to.
+func (s *sqliteWriter) Flush() (err error) {
+	s.committing.Lock()
+	defer s.committing.Unlock()
+	s.mx.Lock()
+	rows := s.rows
+	s.rows = nil
+	s.mx.Unlock()
+	if len(rows) > 0 {
+		if err = s.insert(rows); err != nil {
+			return
+		}
+	}
+	days := loadConfig().sqliteRetention
+	if days > 0 && time.Since(s.pruned) >= sqlitePruneInterval {
+		cutoff := time.Now().AddDate(0, 0, -days).UnixNano()
+		if _, err = s.db.Exec("DELETE FROM logs WHERE ts < ?",
+			cutoff); err != nil {
+			return
+		}
+		s.pruned = time.Now()
+	}
+	return
+}
+
+func (s *sqliteWriter) insert(rows []sqliteRow) (err error) {
+	var tx *sql.Tx
+	if tx, err = s.db.Begin(); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	var st *sql.Stmt
+	if st, err = tx.Prepare("INSERT INTO logs (ts, level, loc, msg, fields) " +
+		"VALUES (?, ?, ?, ?, ?)"); err != nil {
+		return
+	}
+	defer st.Close()
+	for _, r := range rows {
+		if _, err = st.Exec(r.ts, r.level, r.loc, r.msg, r.fields); err != nil {
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+// run commits the waiting rows every commit interval until Close.
+func (s *sqliteWriter) run() {
+	defer close(s.finished)
+	t := time.NewTicker(sqliteCommitInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		}
+		if err := s.Flush(); err != nil {
+			loadConfig().writeError(err)
+		}
+	}
+}
+
+// Close stops the commits every interval, commits the rows still waiting and
+// closes the database.
+func (s *sqliteWriter) Close() (err error) {
+	s.mx.Lock()
+	closed := s.closed
+	s.closed = true
+	s.mx.Unlock()
+	if closed {
+		return
+	}
+	close(s.done)
+	<-s.finished
+	err = s.Flush()
+	if e := s.db.Close(); err == nil {
+		err = e
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package lol_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+// recordingDriver stands in for a SQLite driver, recording the statements
+// executed and the transactions committed.
+type recordingDriver struct {
+	sync.Mutex
+	execs   []string
+	args    [][]driver.Value
+	commits int
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return recordingConn{d}, nil
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(q string) (driver.Stmt, error) {
+	return recordingStmt{c.d, q}, nil
+}
+func (c recordingConn) Close() error              { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c recordingConn) Rollback() error           { return nil }
+
+func (c recordingConn) Commit() error {
+	c.d.Lock()
+	defer c.d.Unlock()
+	c.d.commits++
+	return nil
+}
+
+type recordingStmt struct {
+	d *recordingDriver
+	q string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.Lock()
+	defer s.d.Unlock()
+	s.d.execs = append(s.d.execs, s.q)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+var (
+	sqliteDriver   = &recordingDriver{}
+	registerSQLite sync.Once
+)
+
+func TestSQLite(t *testing.T) {
+	if !slices.Contains(sql.Drivers(), "sqlite") {
+		if _, _, _, err := lol.NewSQLite("logs.db", 0); !errors.Is(err,
+			lol.ErrNoSQLiteDriver) {
+			t.Fatalf("opened without a driver: %v", err)
+		}
+	}
+	registerSQLite.Do(func() { sql.Register("sqlite", sqliteDriver) })
+	d := sqliteDriver
+	d.Lock()
+	d.execs, d.args, d.commits = nil, nil, 0
+	d.Unlock()
+	lol.SetSQLiteRetention(30)
+	defer lol.SetSQLiteRetention(0)
+	l, _, closer, err := lol.NewSQLite("logs.db", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.I.KV("first", "user", "bob")
+	l.W.Ln("second")
+	l.F.Ln("third")
+	l.I.Ln("fourth")
+	if err = closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = closer.Close(); err != nil {
+		t.Fatalf("second close returned %v", err)
+	}
+	d.Lock()
+	defer d.Unlock()
+	if !strings.HasPrefix(d.execs[0], "CREATE TABLE IF NOT EXISTS logs") {
+		t.Fatalf("table not created: %q", d.execs)
+	}
+	var inserts [][]driver.Value
+	var pruned bool
+	for i, q := range d.execs {
+		switch {
+		case strings.HasPrefix(q, "INSERT"):
+			inserts = append(inserts, d.args[i])
+		case strings.HasPrefix(q, "DELETE FROM logs WHERE ts < ?"):
+			pruned = true
+		}
+	}
+	if len(inserts) != 4 || d.commits != 2 || !pruned {
+		t.Fatalf("%d inserts in %d commits, pruned %v", len(inserts),
+			d.commits, pruned)
+	}
+	if inserts[0][1] != "info" || inserts[0][3] != "first" ||
+		inserts[0][4] != `{"user":"bob"}` ||
+		!strings.Contains(inserts[0][2].(string), "sqlite_test.go:") {
+		t.Fatalf("unexpected row %v", inserts[0])
+	}
+}
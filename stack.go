@@ -0,0 +1,92 @@
+package lol
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// SetStackTraceLevel makes lines at level or a more severe one carry the stack
+// of their caller beneath the message, including those from Chk and Err, but
+// not lines that have a detail of their own, such as from Stack and Table.
+// Off, the default, never attaches a stack, and below the level no stack is
+// captured at all.
+func SetStackTraceLevel(level int) {
+	update(func(c *config) { c.stackTraceLevel = int32(level) })
+}
+
+// stackAt is whether lines at level get a stack attached.
+func (c *config) stackAt(level int32) bool {
+	return c.stackTraceLevel != Off && level <= c.stackTraceLevel
+}
+
+// SetStackSkipErrors sets errors, such as io.EOF or context.Canceled, that are
+// expected and so never get a stack attached by Chk, including when they are
+// wrapped. Calling it with no errors removes them all.
+func SetStackSkipErrors(errs ...error) {
+	update(func(c *config) { c.stackSkipErrors = errs })
+}
+
+// chkStack returns the stack to attach to err logged at level, counted from
+// the caller skip frames up, or nothing when no stack is wanted.
+func (c *config) chkStack(level int32, err error, skip int) string {
+	if !c.stackAt(level) {
+		return ""
+	}
+	for _, s := range c.stackSkipErrors {
+		if errors.Is(err, s) {
+			return ""
+		}
+	}
+	return stack(skip + 1)
+}
+
+// GetStack renders the stack of the caller skip frames up, counted the same way
+// as GetLoc, one function and one file:line per frame. Runs of identical
+// adjacent frames, as deep recursion produces, are collapsed into the first
+// frame followed by a "... (xN)" line giving the length of the run.
+func GetStack(skip int) string {
+	return stack(skip)
+}
+
+func stack(skip int) string {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	var prev runtime.Frame
+	var repeats int
+	flush := func() {
+		if repeats > 0 {
+			fmt.Fprintf(&b, "\t... (x%d)\n", repeats+1)
+			repeats = 0
+		}
+	}
+	for i := 0; ; i++ {
+		f, more := frames.Next()
+		if i > 0 && sameFrame(f, prev) {
+			repeats++
+		} else {
+			flush()
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+			prev = f
+		}
+		if !more {
+			break
+		}
+	}
+	flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func sameFrame(a, b runtime.Frame) bool {
+	return a.Function == b.Function && a.File == b.File && a.Line == b.Line
+}
@@ -0,0 +1,76 @@
+package lol_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func recurse(n int, l *lol.Log) {
+	if n == 0 {
+		l.I.Stack("bottom")
+		return
+	}
+	recurse(n-1, l)
+}
+
+func TestStackCollapse(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	recurse(20, l)
+	out := buf.String()
+	if !strings.Contains(out, "bottom ") {
+		t.Fatalf("message missing from %q", out)
+	}
+	if !strings.Contains(out, "... (x20)") {
+		t.Fatalf("recursive frames not collapsed:\n%s", out)
+	}
+	if strings.Count(out, "lol_test.recurse") != 2 {
+		t.Fatalf("expected the two distinct recurse frames once each:\n%s", out)
+	}
+}
+
+func TestChkStack(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	l.E.Chk(errors.New("no stack by default"))
+	if strings.Contains(buf.String(), "TestChkStack") {
+		t.Fatalf("stack attached with the threshold off:\n%s", buf.String())
+	}
+	lol.SetStackTraceLevel(lol.Error)
+	lol.SetStackSkipErrors(io.EOF)
+	defer lol.SetStackTraceLevel(lol.Off)
+	defer lol.SetStackSkipErrors()
+	buf.Reset()
+	l.W.Chk(errors.New("warning"))
+	l.E.Chk(fmt.Errorf("reading: %w", io.EOF))
+	if strings.Contains(buf.String(), "TestChkStack") {
+		t.Fatalf("stack attached below threshold or for a skipped error:\n%s",
+			buf.String())
+	}
+	l.E.Chk(errors.New("surprise"))
+	if !strings.Contains(buf.String(), "lol_test.TestChkStack\n") {
+		t.Fatalf("stack missing for an unexpected error:\n%s", buf.String())
+	}
+}
+
+func TestStackTraceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	lol.SetStackTraceLevel(lol.Error)
+	defer lol.SetStackTraceLevel(lol.Off)
+	l.W.Ln("below")
+	if strings.Contains(buf.String(), "TestStackTraceLevel") {
+		t.Fatalf("stack attached below the threshold:\n%s", buf.String())
+	}
+	_ = l.E.Err("failed %d", 1)
+	l.F.Ln("dying")
+	if strings.Count(buf.String(), "lol_test.TestStackTraceLevel\n") != 2 {
+		t.Fatalf("stacks missing for Err and Fatal:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,48 @@
+package lol
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+// StdLogger is Log.StdLogger for the standard Log that writes to os.Stdout.
+func StdLogger(level int) *log.Logger { return l.StdLogger(level) }
+
+// StdLogger returns a *log.Logger whose lines are logged through the printer
+// of l for level, for libraries that take one, such as the ErrorLog of an
+// http.Server. The logger is made without a prefix or flags, so that lines are
+// not stamped with a time and location twice, and each line is given the
+// location of the code that called the logger rather than one in package log.
+// The trailing newline that log.Logger adds is dropped.
+func (l *Log) StdLogger(level int) *log.Logger {
+	return log.New(&stdWriter{lp: l.At(level)}, "", 0)
+}
+
+// stdWriter is the writer of a logger from StdLogger.
+type stdWriter struct{ lp LevelPrinter }
+
+func (w *stdWriter) Write(b []byte) (n int, err error) {
+	if w.lp.p == nil {
+		return len(b), nil
+	}
+	p := *w.lp.p
+	// the caller is the first frame outside package log, which is skipped
+	// over as the code of a printer would be
+	var pcs [16]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for skip := 0; ; skip++ {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "log.") {
+			p.skip = skip
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	if c := p.enabled(); c != nil {
+		p.emit(c, strings.TrimSuffix(string(b), "\n"), "")
+	}
+	return len(b), nil
+}
@@ -0,0 +1,28 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestStdLogger(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	std := l.StdLogger(lol.Warn)
+	std.Printf("accept error: %s", "too many open files")
+	out := buf.String()
+	if !strings.Contains(out, " accept error: too many open files ") ||
+		!strings.Contains(out, "stdlog_test.go:16") ||
+		strings.Count(out, "\n") != 1 {
+		t.Fatalf("unexpected line %q", out)
+	}
+	buf.Reset()
+	l.StdLogger(lol.Debug).Println("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug line written at info: %q", buf.String())
+	}
+}
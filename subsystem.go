@@ -0,0 +1,55 @@
+package lol
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewNamed is New for a Log of the subsystem name, such as the networking code
+// of a larger program, whose level can be set apart from the rest with
+// SetSubsystemLevel. Until it is, the level from SetLogLevel applies. skip is
+// as for NewUnixgram.
+func NewNamed(name string, w io.Writer, skip int) (l *Log, c *Check) {
+	return newLog(printer{writer: w, skip: skip, name: name})
+}
+
+// SetSubsystemLevel sets the level of the Logs made by NewNamed for name, from
+// a level as SetLevelFromEnv takes it, in place of the level from
+// SetLogLevel. An empty level goes back to the level from
+// SetLogLevel. SetFileLevel still takes precedence for the files it matches.
+func SetSubsystemLevel(name, level string) (err error) {
+	l := int32(-1)
+	if level != "" {
+		parsed, ok := parseLevel(strings.TrimSpace(level))
+		if !ok {
+			return fmt.Errorf("lol: unknown level %q for subsystem %q", level,
+				name)
+		}
+		l = int32(parsed)
+	}
+	update(func(c *config) {
+		levels := make(map[string]int32, len(c.subsystemLevels)+1)
+		for n, sl := range c.subsystemLevels {
+			if n != name {
+				levels[n] = sl
+			}
+		}
+		if l >= Off {
+			levels[name] = l
+		}
+		c.subsystemLevels = levels
+	})
+	return
+}
+
+// GetSubsystemLevel returns the name of the level that the Logs of the
+// subsystem name print at, which is the level from SetLogLevel until one is
+// set with SetSubsystemLevel.
+func GetSubsystemLevel(name string) string {
+	c := loadConfig()
+	if l, ok := c.subsystemLevels[name]; ok {
+		return LevelNames[l]
+	}
+	return LevelNames[max(Off, min(Trace, c.level))]
+}
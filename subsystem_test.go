@@ -0,0 +1,49 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestSubsystemLevel(t *testing.T) {
+	skipInRelease(t)
+	var buf bytes.Buffer
+	prev := lol.GetLogLevel()
+	defer lol.SetLogLevel(prev)
+	lol.SetLogLevel(lol.Info)
+	net, _ := lol.NewNamed("net", &buf, 0)
+	other, _ := lol.New(&buf)
+	if err := lol.SetSubsystemLevel("net", "TRACE"); err != nil {
+		t.Fatal(err)
+	}
+	defer lol.SetSubsystemLevel("net", "")
+	net.T.Ln("packet")
+	other.T.Ln("hidden")
+	if !strings.Contains(buf.String(), "packet") ||
+		strings.Contains(buf.String(), "hidden") {
+		t.Fatalf("subsystem level not applied: %q", buf.String())
+	}
+	if got := lol.GetSubsystemLevel("net"); got != "trace" {
+		t.Fatalf("net at %q", got)
+	}
+	if got := lol.GetSubsystemLevel("disk"); got != "info" {
+		t.Fatalf("unset subsystem at %q", got)
+	}
+	for _, level := range []string{"d", "5", " Debug "} {
+		if err := lol.SetSubsystemLevel("net", level); err != nil ||
+			lol.GetSubsystemLevel("net") != "debug" {
+			t.Fatalf("level %q not taken: %v", level, err)
+		}
+	}
+	err := lol.SetSubsystemLevel("net", "Loud")
+	if err == nil {
+		t.Fatalf("unknown level accepted")
+	}
+	want := `lol: unknown level "Loud" for subsystem "net"`
+	if err.Error() != want {
+		t.Fatalf("error %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,54 @@
+package lol
+
+import (
+	"io"
+	"sync"
+)
+
+// syncWriter is the writer returned by SyncWriter.
+type syncWriter struct {
+	mx sync.Mutex
+	w  io.Writer
+}
+
+// SyncWriter returns a writer that writes to w under a lock of its own, for
+// writers that are not safe to write to from more than one goroutine at once,
+// such as a bytes.Buffer or a bufio.Writer. Logs do not lock their writers,
+// and each line goes to the writer in one Write, so a writer that already
+// takes whole writes atomically, such as an *os.File, needs no wrapping; the
+// wrap is opt-in for the others. The Flush of w, and the levels given to the
+// writers from NewSyslogWriter, still reach it through the wrapper, and it is
+// colored when w is a terminal.
+func SyncWriter(w io.Writer) io.Writer {
+	if s, ok := w.(*syncWriter); ok {
+		return s
+	}
+	return &syncWriter{w: w}
+}
+
+func (s *syncWriter) Write(b []byte) (n int, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.w.Write(b)
+}
+
+func (s *syncWriter) WriteLevel(level int, b []byte) (n int, err error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if lw, ok := s.w.(levelWriter); ok {
+		return lw.WriteLevel(level, b)
+	}
+	return s.w.Write(b)
+}
+
+// Flush flushes w when it is a Flusher.
+func (s *syncWriter) Flush() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if f, ok := s.w.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *syncWriter) wrapped() []io.Writer { return []io.Writer{s.w} }
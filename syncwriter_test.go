@@ -0,0 +1,61 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+	"go.uber.org/atomic"
+)
+
+// overlapWriter is a writer that is not safe for concurrent use, which notes
+// when a write starts while another is still going.
+type overlapWriter struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+	buf     bytes.Buffer
+}
+
+func (o *overlapWriter) Write(b []byte) (int, error) {
+	if o.active.Inc() > 1 {
+		o.overlap.Store(true)
+	}
+	defer o.active.Dec()
+	time.Sleep(time.Millisecond)
+	if o.overlap.Load() {
+		return len(b), nil
+	}
+	return o.buf.Write(b)
+}
+
+func TestSyncWriter(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	ow := &overlapWriter{}
+	l, _ := lol.New(lol.SyncWriter(ow))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				l.I.F("goroutine %d line %d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if ow.overlap.Load() {
+		t.Fatalf("writes through SyncWriter overlapped")
+	}
+	lines := strings.Split(strings.TrimSuffix(ow.buf.String(), "\n"), "\n")
+	if len(lines) != 40 {
+		t.Fatalf("wrote %d lines of 40", len(lines))
+	}
+	for _, line := range lines {
+		if strings.Count(line, "goroutine ") != 1 {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lol
+
+import (
+	"errors"
+	"io"
+)
+
+// NewSyslogWriter returns an error, as there is no syslog on this platform.
+func NewSyslogWriter(tag string) (w io.Writer, err error) {
+	return nil, errors.New("lol: syslog is not available on this platform")
+}
+
+// DialSyslog returns an error, as there is no syslog on this platform.
+func DialSyslog(network, raddr, tag string) (w io.Writer, err error) {
+	return NewSyslogWriter(tag)
+}
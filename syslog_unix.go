@@ -0,0 +1,64 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol
+
+import (
+	"bytes"
+	"io"
+	"log/syslog"
+)
+
+// syslogWriter writes each line to syslog with the priority of its level.
+type syslogWriter struct{ w *syslog.Writer }
+
+// NewSyslogWriter returns a writer for New that sends each line to the local
+// syslog daemon as tag, at the priority for its level: Fatal lines are
+// critical, Error lines errors, Warn lines warnings, Info lines informational
+// and Debug and Trace lines debug. As syslog stamps each message with the time
+// and host itself, the timestamp of text lines is left out.
+func NewSyslogWriter(tag string) (w io.Writer, err error) {
+	return DialSyslog("", "", tag)
+}
+
+// DialSyslog is NewSyslogWriter for the syslog daemon at raddr over network,
+// as for syslog.Dial, such as a central one listening on UDP.
+func DialSyslog(network, raddr, tag string) (w io.Writer, err error) {
+	var s *syslog.Writer
+	if s, err = syslog.Dial(network, raddr, syslog.LOG_USER|syslog.LOG_INFO,
+		tag); err != nil {
+		return
+	}
+	return &syslogWriter{s}, nil
+}
+
+// Write sends b as an informational message, for writes that do not come from
+// a printer.
+func (s *syslogWriter) Write(b []byte) (n int, err error) {
+	return s.WriteLevel(Info, b)
+}
+
+// WriteLevel sends the line b at the priority for level, after whatever comes
+// before its level tag, which is the timestamp.
+func (s *syslogWriter) WriteLevel(level int, b []byte) (n int, err error) {
+	msg := b
+	if i := bytes.Index(b, []byte(LevelSpecs[level].Name+" ")); i >= 0 {
+		msg = b[i:]
+	}
+	m := string(bytes.TrimSuffix(msg, []byte{'\n'}))
+	switch level {
+	case Fatal:
+		err = s.w.Crit(m)
+	case Error:
+		err = s.w.Err(m)
+	case Warn:
+		err = s.w.Warning(m)
+	case Info:
+		err = s.w.Info(m)
+	default:
+		err = s.w.Debug(m)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,39 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lol_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestDialSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	w, err := lol.DialSyslog("udp", conn.LocalAddr().String(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := lol.New(w)
+	l.W.Ln("disk nearly full")
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// user facility and warning priority, and no lol timestamp
+	msg := string(b[:n])
+	if !strings.HasPrefix(msg, "<12>") ||
+		!strings.Contains(msg, " app[") ||
+		!strings.Contains(msg, "]: WRN disk nearly full ") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
@@ -0,0 +1,108 @@
+package lol
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// tableText renders headers and rows for p, with the settings in c, as the
+// message and detail of one entry: the message gives the number of rows and
+// the detail holds the table, with aligned columns for the writers that lines
+// are colored for, as terminals are unless SetColor says otherwise, or as CSV
+// lines for other writers and structured encoders.
+func (p *printer) tableText(c *config, headers []string,
+	rows [][]string) (text, detail string) {
+
+	text = fmt.Sprintf("table of %d rows", len(rows))
+	if len(rows) == 1 {
+		text = "table of 1 row"
+	}
+	// cells are escaped so each row stays on one line
+	escape := strings.NewReplacer("\r", `\r`, "\n", `\n`)
+	table := make([][]string, 0, len(rows)+1)
+	for _, row := range append([][]string{headers}, rows...) {
+		out := make([]string, len(row))
+		for i, cell := range row {
+			out[i] = escape.Replace(cell)
+		}
+		table = append(table, out)
+	}
+	if p.structured(c) || !c.colorFor(p) {
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		_ = w.WriteAll(table)
+		return text, strings.TrimSuffix(b.String(), "\n")
+	}
+	return text, alignTable(table)
+}
+
+// alignTable renders table with each column as wide as its widest cell and a
+// rule beneath the first row, which holds the headers.
+func alignTable(table [][]string) string {
+	var widths []int
+	for _, row := range table {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	var b strings.Builder
+	line := func(cells func(i int) string) {
+		var s strings.Builder
+		for i, w := range widths {
+			c := cells(i)
+			s.WriteString(c)
+			if i < len(widths)-1 {
+				s.WriteString(strings.Repeat(" ",
+					w-utf8.RuneCountInString(c)+2))
+			}
+		}
+		b.WriteString(strings.TrimRight(s.String(), " "))
+	}
+	for r, row := range table {
+		if r > 0 {
+			b.WriteByte('\n')
+		}
+		line(func(i int) string {
+			if i < len(row) {
+				return row[i]
+			}
+			return ""
+		})
+		if r == 0 {
+			b.WriteByte('\n')
+			line(func(i int) string { return strings.Repeat("-", widths[i]) })
+		}
+	}
+	return b.String()
+}
+
+// wrapper is a writer of lol that writes to others, which are terminals when
+// all of them are.
+type wrapper interface{ wrapped() []io.Writer }
+
+// isTerminal is whether w is a character device, such as a terminal, rather
+// than a file, pipe or buffer, or a wrapper of only terminals.
+func isTerminal(w io.Writer) bool {
+	if t, ok := w.(wrapper); ok {
+		ws := t.wrapped()
+		for _, w := range ws {
+			if !isTerminal(w) {
+				return false
+			}
+		}
+		return len(ws) > 0
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
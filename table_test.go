@@ -0,0 +1,40 @@
+package lol_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := lol.New(&buf)
+	rows := [][]string{{"a.txt", "3"}, {"b, c.txt", "two\nlines"}}
+	l.I.Table([]string{"file", "count"}, rows)
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[0], " table of 2 rows ") ||
+		strings.Join(lines[1:], "\n") !=
+			"file,count\na.txt,3\n\"b, c.txt\",two\\nlines\n" {
+		t.Fatalf("unexpected table %q", buf.String())
+	}
+	if rows[1][1] != "two\nlines" {
+		t.Fatalf("rows changed by Table")
+	}
+	lol.SetColor(true)
+	buf.Reset()
+	l.I.Table([]string{"file", "count"}, rows[:1])
+	lol.SetColor(false)
+	if !strings.Contains(buf.String(), "\nfile   count\n") {
+		t.Fatalf("table not aligned with SetColor on: %q", buf.String())
+	}
+	prev := lol.GetLogLevel()
+	defer lol.SetLogLevel(prev)
+	lol.SetLogLevel(lol.Info)
+	buf.Reset()
+	l.D.Table([]string{"gated"}, nil)
+	if buf.Len() != 0 {
+		t.Fatalf("debug table written at info: %q", buf.String())
+	}
+}
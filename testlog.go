@@ -0,0 +1,36 @@
+package lol
+
+import "strings"
+
+// TestLogger is the part of testing.TB that NewTest logs through, so that
+// this package does not import testing into the programs that use it.
+type TestLogger interface {
+	Helper()
+	Log(args ...interface{})
+}
+
+// NewTest returns a Log that logs each line through tb.Log, for code under
+// test, so that its lines are shown with the test that logged them and only
+// when it fails or runs with -v. Lines are rendered as by New, with their
+// level and code location, and are still filtered by the log level. Nothing
+// should be logged through it once the test is over, which testing.T does not
+// allow.
+func NewTest(tb TestLogger) (l *Log, c *Check) {
+	return New(&testWriter{tb})
+}
+
+// NewTestPrinter is NewTest for the Info printer alone, as a PrinterI for
+// code that takes one.
+func NewTestPrinter(tb TestLogger) PrinterI {
+	l, _ := NewTest(tb)
+	return l.I.Printer()
+}
+
+// testWriter is the writer of a Log from NewTest.
+type testWriter struct{ tb TestLogger }
+
+func (t *testWriter) Write(b []byte) (n int, err error) {
+	t.tb.Helper()
+	t.tb.Log(strings.TrimSuffix(string(b), "\n"))
+	return len(b), nil
+}
@@ -0,0 +1,34 @@
+package lol_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+// recordingTB is the part of a testing.TB that NewTest uses, recording what
+// is logged.
+type recordingTB struct{ logs []string }
+
+func (r *recordingTB) Helper()                 {}
+func (r *recordingTB) Log(args ...interface{}) { r.logs = append(r.logs, fmt.Sprint(args...)) }
+
+func TestNewTest(t *testing.T) {
+	lol.SetLogLevel(lol.Info)
+	tb := &recordingTB{}
+	l, _ := lol.NewTest(tb)
+	l.W.F("retrying %s", "db")
+	l.D.Ln("hidden")
+	lol.NewTestPrinter(tb).Ln("through the PrinterI")
+	if len(tb.logs) != 2 || strings.HasSuffix(tb.logs[0], "\n") ||
+		!strings.Contains(tb.logs[0], " retrying db ") ||
+		!strings.Contains(tb.logs[0], "testlog_test.go:22") ||
+		!strings.Contains(tb.logs[1], "testlog_test.go:24") {
+		t.Fatalf("unexpected logs %q", tb.logs)
+	}
+	// a real testing.TB works as well
+	tl, _ := lol.NewTest(t)
+	tl.I.Ln("shown with -v")
+}
@@ -0,0 +1,44 @@
+package lol
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+)
+
+// traceIDKey is the context key of the ID added by WithTraceID.
+type traceIDKey struct{}
+
+// SetIDGenerator sets the function WithTraceID calls for each new ID, such as
+// one making ULIDs so that IDs sort in the order they were made. Passing nil
+// restores NewTraceID.
+func SetIDGenerator(fn func() string) {
+	update(func(c *config) { c.idGenerator = fn })
+}
+
+// NewTraceID is the default ID generator, a random 128 bit token from
+// crypto/rand in unpadded base32.
+func NewTraceID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return base32.StdEncoding.WithPadding(base32.NoPadding).
+		EncodeToString(b[:])
+}
+
+// WithTraceID returns ctx with a new ID from the ID generator, which printers
+// from LevelPrinter.Ctx add to each line as trace_id, so that the lines for
+// one request can be found together.
+func WithTraceID(ctx context.Context) context.Context {
+	gen := loadConfig().idGenerator
+	if gen == nil {
+		gen = NewTraceID
+	}
+	return context.WithValue(ctx, traceIDKey{}, gen())
+}
+
+// TraceID returns the ID added to ctx by WithTraceID, or nothing when there
+// is none.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,49 @@
+package lol
+
+import (
+	"net"
+	"time"
+)
+
+// unixgramRetries is how many times a datagram is resent before the line is
+// given up on and the write error handler called.
+const unixgramRetries = 3
+
+// unixgramWriter sends each Write as a single datagram. Printers write each
+// line with one call to Write, so one line is always one datagram.
+type unixgramWriter struct {
+	conn *net.UnixConn
+}
+
+func (u *unixgramWriter) Write(b []byte) (n int, err error) {
+	for i := 0; i < unixgramRetries; i++ {
+		if n, err = u.conn.Write(b); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(i+1) * time.Millisecond)
+	}
+	return
+}
+
+// NewUnixgram returns a Log that writes to the unix datagram socket at path,
+// such as one a local log shipper listens on, with every line sent as exactly
+// one datagram. skip is the number of extra stack frames between the caller
+// and the printers, for logs that are wrapped in helper functions. Sends that
+// keep failing after a few retries are reported to the write error handler.
+func NewUnixgram(path string, skip int) (l *Log, c *Check, err error) {
+	var w *unixgramWriter
+	if w, err = dialUnixgram(path); err != nil {
+		return
+	}
+	l, c = newLog(printer{writer: w, skip: skip})
+	return
+}
+
+func dialUnixgram(path string) (w *unixgramWriter, err error) {
+	var conn *net.UnixConn
+	if conn, err = net.DialUnix("unixgram", nil,
+		&net.UnixAddr{Name: path, Net: "unixgram"}); err != nil {
+		return
+	}
+	return &unixgramWriter{conn: conn}, nil
+}
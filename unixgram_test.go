@@ -0,0 +1,37 @@
+package lol_test
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mleku/lol"
+)
+
+func TestUnixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram",
+		&net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip("unix datagram sockets unavailable:", err)
+	}
+	defer conn.Close()
+	l, _, err := lol.NewUnixgram(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.I.Ln("first")
+	l.I.Stack("second")
+	buf := make([]byte, 65536)
+	for _, want := range []string{"first", "second"} {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(buf[:n])
+		if !strings.Contains(got, want) || !strings.HasSuffix(got, "\n") {
+			t.Fatalf("datagram %q is not the whole %q line", got, want)
+		}
+	}
+}
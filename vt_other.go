@@ -0,0 +1,9 @@
+//go:build !windows
+
+package lol
+
+import "io"
+
+// enableVT does nothing outside Windows, where terminals render the escape
+// sequences that color lines as they are.
+func enableVT(w io.Writer) {}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package lol
+
+import (
+	"io"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes the
+// console render ANSI escape sequences, such as those that color lines.
+const enableVirtualTerminalProcessing = 0x0004
+
+var setConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc(
+	"SetConsoleMode")
+
+// vtEnabled holds the console handles that enableVT has been called for.
+var vtEnabled sync.Map
+
+// enableVT turns on virtual terminal processing for w when it is a console,
+// or for the consoles it wraps, so that colored lines show as colors rather
+// than raw escape sequences on consoles that do not do so already, once for
+// each console handle.
+func enableVT(w io.Writer) {
+	if t, ok := w.(wrapper); ok {
+		for _, w := range t.wrapped() {
+			enableVT(w)
+		}
+		return
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return
+	}
+	h := syscall.Handle(f.Fd())
+	if _, done := vtEnabled.LoadOrStore(h, struct{}{}); done {
+		return
+	}
+	var mode uint32
+	if syscall.GetConsoleMode(h, &mode) != nil ||
+		mode&enableVirtualTerminalProcessing != 0 {
+		return
+	}
+	_, _, _ = setConsoleMode.Call(uintptr(h),
+		uintptr(mode|enableVirtualTerminalProcessing))
+}
@@ -0,0 +1,224 @@
+package lol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// webhookBatchLines is the batch size of NewWebhook when given none.
+	webhookBatchLines = 100
+	// webhookInterval is the interval of NewWebhook when given none.
+	webhookInterval = 5 * time.Second
+	// webhookMaxQueued is how many lines are held while the endpoint cannot
+	// be reached, beyond which the oldest are dropped.
+	webhookMaxQueued = 10000
+	// webhookAttempts is how many times a batch is sent before it is put back
+	// to wait for the next send.
+	webhookAttempts = 4
+	// webhookBackoff is the wait after the first failed attempt, doubling
+	// after each one after that.
+	webhookBackoff = 200 * time.Millisecond
+)
+
+// SetWebhookClient sets the http.Client that webhooks from NewWebhook send
+// with, such as one with client certificates or a transport that adds
+// authentication. Passing nil restores http.DefaultClient.
+func SetWebhookClient(client *http.Client) {
+	update(func(c *config) { c.webhookClient = client })
+}
+
+// Webhook sends the JSON records of a Log from NewWebhook to an HTTP endpoint
+// in batches.
+type Webhook struct {
+	url string
+	// batchLines is the most lines sent in one request, and how many waiting
+	// lines start a send without waiting for the next tick, which comes every
+	// interval.
+	batchLines int
+	interval   time.Duration
+	mx         sync.Mutex
+	// queue holds the lines waiting to be sent, oldest first.
+	queue   [][]byte
+	dropped int
+	// sending is held for the whole of a send, so that Flush returns only
+	// once the lines written before it have been tried.
+	sending  sync.Mutex
+	kick     chan struct{}
+	done     chan struct{}
+	finished chan struct{}
+	once     sync.Once
+	err      error
+}
+
+// NewWebhook returns a Log that POSTs its entries to endpoint as JSON arrays
+// of the records of JSONEncoder, for environments with no log agent. Lines are
+// sent every interval, or once batchLines are waiting, in requests of at most
+// batchLines lines; a batchLines below 1 is 100 and an interval of zero or less
+// is five seconds. A batch that fails is retried a few times with a growing
+// wait, and when the endpoint is still failing it is held for the next send,
+// keeping at most ten thousand lines and dropping the oldest beyond that. A
+// batch refused with a 4xx status other than 429 is dropped without retrying,
+// as sending it again would not help. Fatal lines and SetFlushLevel send
+// straight away, and Close sends what is left. skip is as for NewUnixgram.
+func NewWebhook(endpoint string, batchLines int, interval time.Duration,
+	skip int) (l *Log, c *Check, w *Webhook, err error) {
+
+	var u *url.URL
+	if u, err = url.Parse(endpoint); err != nil {
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		err = fmt.Errorf("lol: webhook %q is not an http or https URL", endpoint)
+		return
+	}
+	if batchLines < 1 {
+		batchLines = webhookBatchLines
+	}
+	if interval <= 0 {
+		interval = webhookInterval
+	}
+	w = &Webhook{
+		url:        endpoint,
+		batchLines: batchLines,
+		interval:   interval,
+		kick:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		finished:   make(chan struct{}),
+	}
+	go w.run()
+	l, c = newLog(printer{writer: w, encoder: JSONEncoder, skip: skip})
+	return
+}
+
+func (w *Webhook) Write(b []byte) (n int, err error) {
+	line := bytes.TrimRight(b, "\n")
+	w.mx.Lock()
+	w.queue = append(w.queue, append([]byte(nil), line...))
+	if over := len(w.queue) - webhookMaxQueued; over > 0 {
+		w.queue = w.queue[over:]
+		w.dropped += over
+	}
+	full := len(w.queue) >= w.batchLines
+	w.mx.Unlock()
+	if full {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends the lines that are waiting, stopping at the first batch that
+// still fails after its retries, which is kept to be sent again unless the
+// endpoint refused it. Lines dropped since the last Flush are reported along
+// with any error of the send.
+func (w *Webhook) Flush() (err error) {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+	for {
+		w.mx.Lock()
+		if w.dropped > 0 {
+			err = fmt.Errorf("lol: webhook dropped %d lines that could not "+
+				"be sent", w.dropped)
+			w.dropped = 0
+		}
+		n := min(len(w.queue), w.batchLines)
+		batch := w.queue[:n:n]
+		w.queue = w.queue[n:]
+		w.mx.Unlock()
+		if n == 0 {
+			return
+		}
+		if retry, e := w.send(batch); e != nil {
+			err = errors.Join(err, e)
+			if !retry {
+				return
+			}
+			w.mx.Lock()
+			w.queue = append(batch, w.queue...)
+			if over := len(w.queue) - webhookMaxQueued; over > 0 {
+				w.queue = w.queue[over:]
+				w.dropped += over
+			}
+			w.mx.Unlock()
+			return
+		}
+	}
+}
+
+// send POSTs batch, retrying with backoff while the endpoint fails, returning
+// whether it is still worth retrying.
+func (w *Webhook) send(batch [][]byte) (retry bool, err error) {
+	body := append(append([]byte{'['}, bytes.Join(batch, []byte{','})...), ']')
+	client := loadConfig().webhookClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	wait := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		if retry, err = w.post(client, body); err == nil || !retry ||
+			attempt == webhookAttempts {
+			return
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// post makes one attempt at sending body, returning whether a failure is worth
+// retrying.
+func (w *Webhook) post(client *http.Client, body []byte) (retry bool,
+	err error) {
+
+	var resp *http.Response
+	if resp, err = client.Post(w.url, "application/json",
+		bytes.NewReader(body)); err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return
+	}
+	retry = resp.StatusCode >= 500 ||
+		resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("lol: webhook responded %s", resp.Status)
+}
+
+// run sends the waiting lines every interval, or sooner when a batch fills,
+// until Close.
+func (w *Webhook) run() {
+	defer close(w.finished)
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			w.err = w.Flush()
+			return
+		case <-t.C:
+		case <-w.kick:
+		}
+		if err := w.Flush(); err != nil {
+			loadConfig().writeError(err)
+		}
+	}
+}
+
+// Close sends the lines that are still waiting and stops sending, returning
+// the error of that last send. Lines written after Close are not sent.
+func (w *Webhook) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		<-w.finished
+	})
+	return w.err
+}
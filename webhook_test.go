@@ -0,0 +1,119 @@
+package lol_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mleku/lol"
+)
+
+func TestWebhook(t *testing.T) {
+	var mx sync.Mutex
+	var requests int
+	var got []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		mx.Lock()
+		defer mx.Unlock()
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		var batch []map[string]interface{}
+		if err := json.Unmarshal(b, &batch); err != nil {
+			t.Errorf("%v in %s", err, b)
+		}
+		got = append(got, batch...)
+	}))
+	defer srv.Close()
+	lol.SetWebhookClient(srv.Client())
+	defer lol.SetWebhookClient(nil)
+	l, _, w, err := lol.NewWebhook(srv.URL, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.I.Ln("first")
+	l.W.KV("second", "n", 2)
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mx.Lock()
+	defer mx.Unlock()
+	if requests != 2 || len(got) != 2 || got[0]["msg"] != "first" ||
+		got[1]["level"] != "warn" {
+		t.Fatalf("%d requests delivered %v", requests, got)
+	}
+	if _, _, _, err = lol.NewWebhook("ftp://example.com", 0, 0, 0); err == nil {
+		t.Fatalf("accepted a URL that is not http")
+	}
+}
+
+func TestWebhookTriggers(t *testing.T) {
+	batches := make(chan int, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		var batch []json.RawMessage
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &batch)
+		batches <- len(batch)
+	}))
+	defer srv.Close()
+	lol.SetWebhookClient(srv.Client())
+	defer lol.SetWebhookClient(nil)
+	for _, tc := range []struct {
+		name       string
+		batchLines int
+		interval   time.Duration
+		lines      int
+	}{
+		{"batch", 2, time.Hour, 2},
+		{"interval", 1000, 10 * time.Millisecond, 1},
+	} {
+		l, _, w, err := lol.NewWebhook(srv.URL, tc.batchLines, tc.interval, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < tc.lines; i++ {
+			l.I.Ln("line", i)
+		}
+		select {
+		case n := <-batches:
+			if n != tc.lines {
+				t.Errorf("%s: batch of %d lines, want %d", tc.name, n,
+					tc.lines)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: nothing sent before Close", tc.name)
+		}
+		_ = w.Close()
+	}
+}
+
+func TestWebhookDropped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	lol.SetWebhookClient(srv.Client())
+	defer lol.SetWebhookClient(nil)
+	l, _, w, err := lol.NewWebhook(srv.URL, 20000, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10001; i++ {
+		l.I.Ln("line", i)
+	}
+	err = w.Close()
+	if err == nil || !strings.Contains(err.Error(), "dropped 1 lines") ||
+		!strings.Contains(err.Error(), "503") {
+		t.Fatalf("close returned %v", err)
+	}
+}